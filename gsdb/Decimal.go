@@ -0,0 +1,196 @@
+package gsdb
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"math/big"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Decimal is an exact decimal number, for DECIMAL columns such as money, where float64 would pick up rounding
+// errors. It's kept as the decimal text the database uses, so values are read and written without loss, and
+// the scale is kept (12.3400 stays 12.3400). The zero value is 0.
+//
+// Decimal implements sql.Scanner and driver.Valuer. gsdb reads any field whose pointer implements sql.Scanner
+// and writes any field that implements driver.Valuer, but only a Decimal is written as a number: string values
+// from other Valuers are written in hex, as text.
+type Decimal struct {
+	value string
+}
+
+var decimalPattern = regexp.MustCompile(`^[+-]?(\d+\.?\d*|\.\d+)$`)
+
+// ParseDecimal parses a plain decimal number such as -123.4500. Exponents are not accepted.
+func ParseDecimal(s string) (Decimal, error) {
+
+	s = strings.TrimSpace(s)
+	if !decimalPattern.MatchString(s) {
+		return Decimal{}, fmt.Errorf("invalid decimal %q", s)
+	}
+
+	negative := strings.HasPrefix(s, "-")
+	s = strings.TrimLeft(s, "+-")
+
+	whole, fraction, _ := strings.Cut(s, ".")
+	whole = strings.TrimLeft(whole, "0")
+	if whole == "" {
+		whole = "0"
+	}
+	if fraction != "" {
+		whole = whole + "." + fraction
+	}
+	if negative && strings.Trim(whole, "0.") != "" {
+		whole = "-" + whole
+	}
+	return Decimal{value: whole}, nil
+}
+
+// NewDecimal makes a Decimal from an unscaled integer and a scale, so NewDecimal(12345, 2) is 123.45
+func NewDecimal(unscaled int64, scale int) Decimal {
+
+	s := strconv.FormatInt(unscaled, 10)
+	if scale <= 0 {
+		d, _ := ParseDecimal(s + strings.Repeat("0", -scale))
+		return d
+	}
+
+	negative := strings.HasPrefix(s, "-")
+	s = strings.TrimPrefix(s, "-")
+	if len(s) <= scale {
+		s = strings.Repeat("0", scale-len(s)+1) + s
+	}
+	s = s[:len(s)-scale] + "." + s[len(s)-scale:]
+	if negative {
+		s = "-" + s
+	}
+	d, _ := ParseDecimal(s)
+	return d
+}
+
+// String returns the decimal as text, e.g. 123.4500
+func (d Decimal) String() string {
+	if d.value == "" {
+		return "0"
+	}
+	return d.value
+}
+
+// IsZero reports whether the decimal is zero, whatever its scale
+func (d Decimal) IsZero() bool {
+	return strings.Trim(d.String(), "-0.") == ""
+}
+
+// Rat returns the exact value as a *big.Rat, for arithmetic
+func (d Decimal) Rat() *big.Rat {
+	r, _ := new(big.Rat).SetString(d.String())
+	return r
+}
+
+// Cmp compares two decimals by value, returning -1, 0 or +1. 1.50 and 1.5 are equal.
+func (d Decimal) Cmp(other Decimal) int {
+	return d.Rat().Cmp(other.Rat())
+}
+
+// Float64 returns the nearest float64, which may not be exact
+func (d Decimal) Float64() float64 {
+	f, _ := d.Rat().Float64()
+	return f
+}
+
+// Value implements driver.Valuer
+func (d Decimal) Value() (driver.Value, error) {
+	return d.String(), nil
+}
+
+// Scan implements sql.Scanner, reading the string or []byte form drivers use for DECIMAL columns
+func (d *Decimal) Scan(src any) error {
+
+	var err error
+	switch v := src.(type) {
+	case nil:
+		*d = Decimal{}
+	case string:
+		*d, err = ParseDecimal(v)
+	case []byte:
+		*d, err = ParseDecimal(string(v))
+	case int64:
+		*d = NewDecimal(v, 0)
+	case float64:
+		*d, err = ParseDecimal(strconv.FormatFloat(v, 'f', -1, 64))
+	default:
+		err = fmt.Errorf("cannot scan %T into a Decimal", src)
+	}
+	return err
+}
+
+// AsDecimal returns the value as a Decimal, or zero if it's NULL or can't be converted
+func (F Field) AsDecimal() Decimal {
+	d, _ := F.Decimal()
+	return d
+}
+
+// Decimal returns the value as a Decimal, with a *ConversionError for NULL and values that aren't decimals
+func (F Field) Decimal() (Decimal, error) {
+
+	if F.Value == nil {
+		return Decimal{}, F.conversionError("Decimal", nil)
+	}
+
+	var d Decimal
+	if err := d.Scan(F.Value); err != nil {
+		return Decimal{}, F.conversionError("Decimal", err)
+	}
+	return d, nil
+}
+
+// valuerLiteral makes the SQL literal for a value that implements driver.Valuer, such as Decimal. ok is false
+// if the value isn't a Valuer, so the caller can carry on with the usual types.
+func (db *Database) valuerLiteral(value any) (literal string, ok bool, err error) {
+
+	// UUIDs are written in the form the Database stores them
+	if p, ok := value.(*UUID); ok && p != nil {
+		value = *p
+	}
+	if u, ok := uuidValue(value).(UUID); ok {
+		return u.sqlLiteral(db), true, nil
+	}
+
+	valuer, ok := value.(driver.Valuer)
+	if !ok {
+		return "", false, nil
+	}
+
+	if v := reflect.ValueOf(value); v.Kind() == reflect.Pointer && v.IsNil() {
+		return "NULL", true, nil
+	}
+
+	driverValue, err := valuer.Value()
+	if err != nil {
+		return "", true, err
+	}
+
+	switch dv := driverValue.(type) {
+	case nil:
+		return "NULL", true, nil
+	case int64, float64, bool:
+		return fmt.Sprintf("%v", dv), true, nil
+	case string:
+		// A Decimal is written as a quoted literal, so the database converts it exactly. Other strings are
+		// written in hex, whatever they look like.
+		switch value.(type) {
+		case Decimal, *Decimal:
+			return "'" + dv + "'", true, nil
+		}
+		return hexRepresentation(dv), true, nil
+	case []byte:
+		return fmt.Sprintf("X'%x'", dv), true, nil
+	case time.Time:
+		return fmt.Sprintf("'%s'", db.formatTime(dv)), true, nil
+	default:
+		return "", true, fmt.Errorf("unsupported driver value %T", driverValue)
+	}
+}
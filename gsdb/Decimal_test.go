@@ -0,0 +1,120 @@
+package gsdb
+
+import (
+	"context"
+	"database/sql/driver"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type LedgerEntry struct {
+	Id     int      `db:"column=id primarykey=yes table=ledger"`
+	Amount Decimal  `db:"column=amount"`
+	Fee    *Decimal `db:"column=fee"`
+}
+
+func TestParseDecimal(t *testing.T) {
+	tests := map[string]string{
+		"12.3400":                   "12.3400",
+		"+007.50":                   "7.50",
+		".5":                        "0.5",
+		"-0.00":                     "0.00",
+		"-1":                        "-1",
+		"100":                       "100",
+		" 1.25 \n":                  "1.25",
+		"12345678901234567890.1234": "12345678901234567890.1234",
+	}
+	for in, expected := range tests {
+		d, err := ParseDecimal(in)
+		assert.NoError(t, err, in)
+		assert.Equal(t, expected, d.String(), in)
+	}
+
+	for _, in := range []string{"", "abc", "1e5", "1.2.3", "--1"} {
+		_, err := ParseDecimal(in)
+		assert.Error(t, err, in)
+	}
+
+	assert.Equal(t, "123.45", NewDecimal(12345, 2).String())
+	assert.Equal(t, "-0.05", NewDecimal(-5, 2).String())
+	assert.Equal(t, "1200", NewDecimal(12, -2).String())
+	assert.Equal(t, "0", Decimal{}.String())
+	assert.True(t, NewDecimal(0, 4).IsZero())
+	assert.Equal(t, 0, NewDecimal(150, 2).Cmp(NewDecimal(15, 1)))
+	assert.Equal(t, -1, NewDecimal(1, 0).Cmp(NewDecimal(11, 1)))
+}
+
+func TestFieldAsDecimal(t *testing.T) {
+	assert.Equal(t, "19.9900", Field{Value: []byte("19.9900")}.AsDecimal().String())
+	assert.Equal(t, "42", Field{Value: int64(42)}.AsDecimal().String())
+	assert.Equal(t, "0", Field{}.AsDecimal().String())
+
+	_, err := Field{Value: "abc"}.Decimal()
+	assert.EqualError(t, err, `cannot convert string "abc" to Decimal: invalid decimal "abc"`)
+}
+
+func TestDecimalInsertUpdate(t *testing.T) {
+	fee := NewDecimal(5, 4)
+	entry := LedgerEntry{Id: 3, Amount: NewDecimal(-12345678901234567, 4), Fee: &fee}
+
+	sql, err := DB.Insert(entry)
+	assert.NoError(t, err)
+	assert.Equal(t, "INSERT INTO ledger(amount,fee) VALUES ('-1234567890123.4567','0.0005');", sql)
+
+	entry.Fee = nil
+	sql, err = DB.Update(entry)
+	assert.NoError(t, err)
+	assert.Equal(t, "UPDATE ledger SET amount='-1234567890123.4567',fee=NULL WHERE id=3;", sql)
+}
+
+// Code is a Valuer whose text happens to look like a number
+type Code string
+
+func (c Code) Value() (driver.Value, error) {
+	return string(c), nil
+}
+
+func TestValuerStringsWrittenInHex(t *testing.T) {
+	entry := struct {
+		Id   int  `db:"column=id primarykey=yes table=codes"`
+		Code Code `db:"column=code"`
+	}{1, "0123"}
+
+	sql, err := DB.Insert(entry)
+	assert.NoError(t, err)
+	assert.Equal(t, "INSERT INTO codes(code) VALUES (X'30313233');", sql)
+}
+
+func TestDecimalQueryStruct(t *testing.T) {
+	NewSQLite3("test.db", slog.Default(), context.Background())
+
+	_, err := DB.dbConnection.Exec("DROP TABLE IF EXISTS ledger;")
+	assert.NoError(t, err)
+	_, err = DB.dbConnection.Exec("CREATE TABLE ledger (id INTEGER PRIMARY KEY AUTOINCREMENT, amount TEXT, fee TEXT);")
+	assert.NoError(t, err)
+
+	amount, _ := ParseDecimal("98765432109876543.2100")
+	sql, err := DB.Insert(LedgerEntry{Amount: amount})
+	assert.NoError(t, err)
+	_, _, err = DB.Execute(sql)
+	assert.NoError(t, err)
+
+	read, err := QuerySingleStruct[LedgerEntry]("SELECT * FROM ledger")
+	assert.NoError(t, err)
+	assert.Equal(t, "98765432109876543.2100", read.Amount.String())
+	assert.Nil(t, read.Fee)
+
+	fee := NewDecimal(25, 2)
+	sql, err = DB.Update(LedgerEntry{Id: read.Id, Amount: amount, Fee: &fee})
+	assert.NoError(t, err)
+	_, _, err = DB.Execute(sql)
+	assert.NoError(t, err)
+
+	read, err = QuerySingleStruct[LedgerEntry]("SELECT * FROM ledger")
+	assert.NoError(t, err)
+	if assert.NotNil(t, read.Fee) {
+		assert.Equal(t, "0.25", read.Fee.String())
+	}
+}
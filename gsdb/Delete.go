@@ -0,0 +1,145 @@
+package gsdb
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"time"
+)
+
+// structKey holds what's needed to build a statement that targets a single row
+type structKey struct {
+	table            string
+	column           string
+	value            any
+	softDeleteColumn string
+	softDeleteField  int
+}
+
+// getStructKey finds the table, primary key and soft delete column of a structure
+func (db *Database) getStructKey(dbStructure any) (structKey, error) {
+
+	key := structKey{softDeleteField: -1}
+	t := reflect.TypeOf(dbStructure)
+	if t == nil || t.Kind() != reflect.Struct {
+		return key, errors.New("structure expected")
+	}
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		dbStructureMap := decodeTag(field.Tag.Get("db"))
+
+		if !reflect.ValueOf(dbStructure).Field(i).CanInterface() || !isColumn(dbStructureMap) {
+			continue
+		}
+
+		column := db.columnName(field, dbStructureMap)
+		if column == "" {
+			return key, errors.New("no column name specified for field " + field.Name)
+		}
+
+		if dbStructureMap["table"] != "" {
+			key.table = dbStructureMap["table"]
+		}
+
+		if dbStructureMap["primarykey"] == "yes" {
+			key.column = column
+			key.value = reflect.ValueOf(dbStructure).Field(i).Interface()
+		}
+
+		if dbStructureMap["softdelete"] == "yes" {
+			if field.Type != reflect.TypeOf(time.Time{}) && field.Type.Kind() != reflect.Bool {
+				return key, errors.New("softdelete field " + field.Name + " must be a time.Time or bool")
+			}
+			key.softDeleteColumn = column
+			key.softDeleteField = i
+		}
+	}
+
+	key.table = tableName(dbStructure, key.table)
+	if key.table == "" {
+		return key, fmt.Errorf("no table found in structure")
+	}
+	if key.column == "" {
+		return key, fmt.Errorf("no primary key set, unable to set a where clause")
+	}
+	return key, nil
+}
+
+// notDeletedCondition is the WHERE condition that excludes soft deleted rows
+func notDeletedCondition(t reflect.Type, key structKey) string {
+	if t.Field(key.softDeleteField).Type.Kind() == reflect.Bool {
+		return key.softDeleteColumn + "=false"
+	}
+	return key.softDeleteColumn + " IS NULL"
+}
+
+// Delete generates an SQL statement that deletes the structure from its table, using the primary key.
+// If the structure has a softdelete=yes field, the row is marked as deleted with an UPDATE instead, using
+// the current time for a time.Time field or true for a bool. Pass a pointer to have the field set in the struct.
+func (db *Database) Delete(dbStructure any) (string, error) {
+	return db.softDeleteSql(dbStructure, true)
+}
+
+// Restore generates an SQL statement that brings back a soft deleted row, by setting the softdelete=yes field
+// back to NULL (or false). Pass a pointer to have the field cleared in the struct.
+func (db *Database) Restore(dbStructure any) (string, error) {
+	return db.softDeleteSql(dbStructure, false)
+}
+
+func (db *Database) softDeleteSql(dbStructure any, deleted bool) (string, error) {
+
+	v := reflect.ValueOf(structPointer(dbStructure))
+	if v.Kind() == reflect.Pointer && !v.IsNil() {
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return "", errors.New("structure expected")
+	}
+
+	if deleted {
+		if err := beforeDelete(v.Addr().Interface()); err != nil {
+			return "", err
+		}
+	}
+
+	key, err := db.getStructKey(v.Interface())
+	if err != nil {
+		return "", err
+	}
+
+	keyValue, err := db.keyLiteral(key.value)
+	if err != nil {
+		return "", err
+	}
+	where := fmt.Sprintf(" WHERE %s=%s;", key.column, keyValue)
+
+	if key.softDeleteField == -1 {
+		if !deleted {
+			return "", errors.New("no softdelete field found in structure")
+		}
+		return "DELETE FROM " + key.table + where, nil
+	}
+
+	field := v.Field(key.softDeleteField)
+	value := "NULL"
+	var newValue reflect.Value
+
+	switch {
+	case field.Kind() == reflect.Bool:
+		value = fmt.Sprintf("%v", deleted)
+		newValue = reflect.ValueOf(deleted)
+	case deleted:
+		now := db.now()
+		value = fmt.Sprintf("'%s'", db.formatTime(now))
+		newValue = reflect.ValueOf(now)
+	default:
+		newValue = reflect.ValueOf(time.Time{})
+	}
+
+	if field.CanSet() {
+		field.Set(newValue)
+	}
+
+	return "UPDATE " + key.table + " SET " + key.softDeleteColumn + "=" + value + where, nil
+}
@@ -0,0 +1,131 @@
+package gsdb
+
+import (
+	"context"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type SoftDeletePerson struct {
+	Id      int       `db:"column=id primarykey=yes table=Users"`
+	Name    string    `db:"column=name"`
+	Deleted time.Time `db:"column=deleted_at softdelete=yes"`
+}
+
+type SoftDeleteFlagPerson struct {
+	Id      int    `db:"column=id primarykey=yes table=Users"`
+	Name    string `db:"column=name"`
+	Deleted bool   `db:"column=deleted softdelete=yes"`
+}
+
+func TestDelete(t *testing.T) {
+	now := setupClock()
+
+	hard := struct {
+		Id   int    `db:"column=id primarykey=yes table=Users"`
+		Name string `db:"column=name"`
+	}{4, "Test"}
+	sql, err := DB.Delete(hard)
+	assert.NoError(t, err)
+	assert.Equal(t, "DELETE FROM Users WHERE id=4;", sql)
+
+	_, err = DB.Restore(hard)
+	assert.EqualError(t, err, "no softdelete field found in structure")
+
+	entry := SoftDeletePerson{Id: 4, Name: "Test"}
+	sql, err = DB.Delete(&entry)
+	assert.NoError(t, err)
+	assert.Equal(t, "UPDATE Users SET deleted_at='2025-06-01 12:30:45' WHERE id=4;", sql)
+	assert.Equal(t, now, entry.Deleted)
+
+	sql, err = DB.Restore(&entry)
+	assert.NoError(t, err)
+	assert.Equal(t, "UPDATE Users SET deleted_at=NULL WHERE id=4;", sql)
+	assert.True(t, entry.Deleted.IsZero())
+
+	flag := SoftDeleteFlagPerson{Id: 4, Name: "Test"}
+	sql, err = DB.Delete(flag)
+	assert.NoError(t, err)
+	assert.Equal(t, "UPDATE Users SET deleted=true WHERE id=4;", sql)
+	assert.False(t, flag.Deleted, "a struct passed by value must not be changed")
+
+	sql, err = DB.Restore(flag)
+	assert.NoError(t, err)
+	assert.Equal(t, "UPDATE Users SET deleted=false WHERE id=4;", sql)
+
+	// A zero soft delete time is written as NULL
+	sql, err = DB.Insert(SoftDeletePerson{Name: "Test"})
+	assert.NoError(t, err)
+	assert.Equal(t, "INSERT INTO Users(name,deleted_at) VALUES (X'54657374',NULL);", sql)
+
+	// and Update leaves it alone, so a deleted row stays deleted
+	sql, err = DB.Update(SoftDeletePerson{Id: 4, Name: "Test"})
+	assert.NoError(t, err)
+	assert.Equal(t, "UPDATE Users SET name=X'54657374' WHERE id=4;", sql)
+	sql, err = DB.Update(SoftDeleteFlagPerson{Id: 4, Name: "Test"})
+	assert.NoError(t, err)
+	assert.Equal(t, "UPDATE Users SET name=X'54657374' WHERE id=4;", sql)
+}
+
+func TestDeleteErrors(t *testing.T) {
+	New("test/test", slog.Default(), context.Background())
+
+	_, err := DB.Delete(struct {
+		Id int `db:"column=id table=Users"`
+	}{1})
+	assert.EqualError(t, err, "no primary key set, unable to set a where clause")
+
+	_, err = DB.Delete(struct {
+		Id int `db:"column=id primarykey=yes"`
+	}{1})
+	assert.EqualError(t, err, "no table found in structure")
+
+	_, err = DB.Delete(struct {
+		Id      int    `db:"column=id primarykey=yes table=Users"`
+		Deleted string `db:"column=deleted softdelete=yes"`
+	}{1, ""})
+	assert.EqualError(t, err, "softdelete field Deleted must be a time.Time or bool")
+
+	_, err = DB.Delete(1)
+	assert.EqualError(t, err, "structure expected")
+}
+
+type CodeEntry struct {
+	Code string `db:"column=code primarykey=yes table=codes"`
+	Name string `db:"column=name"`
+}
+
+func TestDeleteStringKey(t *testing.T) {
+	NewSQLite3("test.db", slog.Default(), context.Background())
+
+	// String keys are written in hex, so a quote in the key can't end the literal
+	sql, err := DB.Delete(CodeEntry{Code: "abc' OR '1'='1"})
+	assert.NoError(t, err)
+	assert.Equal(t, "DELETE FROM codes WHERE code=CAST(X'61626327204f52202731273d2731' AS CHAR);", sql)
+
+	for _, statement := range []string{
+		"DROP TABLE IF EXISTS codes;",
+		"CREATE TABLE codes (code VARCHAR(32) PRIMARY KEY, name TEXT);",
+		"INSERT INTO codes (code, name) VALUES ('abc', 'First'), ('def', 'Second');",
+	} {
+		_, err = DB.dbConnection.Exec(statement)
+		assert.NoError(t, err)
+	}
+
+	_, rows, err := DB.Execute(sql)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(0), rows)
+
+	sql, err = DB.Delete(CodeEntry{Code: "abc"})
+	assert.NoError(t, err)
+	_, rows, err = DB.Execute(sql)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), rows)
+
+	remaining, err := QueryStruct[CodeEntry]("SELECT code, name FROM codes")
+	assert.NoError(t, err)
+	assert.Equal(t, []CodeEntry{{Code: "def", Name: "Second"}}, remaining)
+}
@@ -0,0 +1,148 @@
+package gsdb
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/mattn/go-sqlite3"
+)
+
+// Error is returned for statements that fail in the database. It says which statement failed, and wraps the error
+// from the driver, which can still be reached with errors.As. The SQL has its sensitive values masked. The message
+// is the driver's own (also masked), so existing checks on it keep working.
+//
+// Use IsDuplicateKey, IsForeignKeyViolation, IsNotNullViolation, IsDeadlock, IsLockTimeout and IsConnectionLost
+// to check what went wrong, the same way for MySQL and SQLite.
+type Error struct {
+	Op    string // the statement, e.g. insert, select, begin
+	Table string // the first table in the statement, if there is one
+	SQL   string
+	Err   error
+}
+
+func (e *Error) Error() string {
+	return e.Err.Error()
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// wrapError turns an error from the driver into an *Error
+func (db *Database) wrapError(err error, sql string) error {
+
+	if err == nil {
+		return nil
+	}
+
+	op, table := statementLabels(sql)
+	return &Error{Op: op, Table: table, SQL: db.redact(sql), Err: db.redactError(err, sql)}
+}
+
+// MySQL error numbers
+const (
+	mysqlDuplicateEntry     = 1062
+	mysqlDuplicateEntryKey  = 1586
+	mysqlRowIsReferenced    = 1451
+	mysqlNoReferencedRow    = 1452
+	mysqlRowIsReferencedOld = 1217
+	mysqlNoReferencedRowOld = 1216
+	mysqlBadNull            = 1048
+	mysqlNoDefaultForField  = 1364
+	mysqlLockWaitTimeout    = 1205
+	mysqlDeadlock           = 1213
+	mysqlServerShutdown     = 1053
+	mysqlConnectionKilled   = 1927
+	mysqlServerGone         = 2006
+	mysqlServerLost         = 2013
+	mysqlClientTimeout      = 4031
+)
+
+func mysqlNumber(err error) (uint16, bool) {
+	var me *mysql.MySQLError
+	if errors.As(err, &me) {
+		return me.Number, true
+	}
+	return 0, false
+}
+
+func sqliteError(err error) (sqlite3.Error, bool) {
+	var se sqlite3.Error
+	if errors.As(err, &se) {
+		return se, true
+	}
+	return se, false
+}
+
+// IsDuplicateKey reports whether a unique or primary key constraint was violated
+func IsDuplicateKey(err error) bool {
+	if n, ok := mysqlNumber(err); ok {
+		return n == mysqlDuplicateEntry || n == mysqlDuplicateEntryKey
+	}
+	if se, ok := sqliteError(err); ok {
+		return se.ExtendedCode == sqlite3.ErrConstraintUnique || se.ExtendedCode == sqlite3.ErrConstraintPrimaryKey
+	}
+	return false
+}
+
+// IsForeignKeyViolation reports whether a foreign key constraint was violated, either by a row referring to a
+// missing parent or by removing a parent that is still referred to
+func IsForeignKeyViolation(err error) bool {
+	if n, ok := mysqlNumber(err); ok {
+		return n == mysqlRowIsReferenced || n == mysqlNoReferencedRow || n == mysqlRowIsReferencedOld || n == mysqlNoReferencedRowOld
+	}
+	if se, ok := sqliteError(err); ok {
+		return se.ExtendedCode == sqlite3.ErrConstraintForeignKey
+	}
+	return false
+}
+
+// IsNotNullViolation reports whether NULL (or nothing) was written to a NOT NULL column
+func IsNotNullViolation(err error) bool {
+	if n, ok := mysqlNumber(err); ok {
+		return n == mysqlBadNull || n == mysqlNoDefaultForField
+	}
+	if se, ok := sqliteError(err); ok {
+		return se.ExtendedCode == sqlite3.ErrConstraintNotNull
+	}
+	return false
+}
+
+// IsDeadlock reports whether the transaction was chosen as a deadlock victim (or, for SQLite, could not continue
+// because another connection changed the database), so the whole transaction should be retried
+func IsDeadlock(err error) bool {
+	if n, ok := mysqlNumber(err); ok {
+		return n == mysqlDeadlock
+	}
+	if se, ok := sqliteError(err); ok {
+		return se.ExtendedCode == sqlite3.ErrBusySnapshot || se.ExtendedCode == sqlite3.ErrLockedSharedCache
+	}
+	return false
+}
+
+// IsLockTimeout reports whether the statement gave up waiting for a lock (SQLITE_BUSY for SQLite)
+func IsLockTimeout(err error) bool {
+	if n, ok := mysqlNumber(err); ok {
+		return n == mysqlLockWaitTimeout
+	}
+	if se, ok := sqliteError(err); ok {
+		return (se.Code == sqlite3.ErrBusy || se.Code == sqlite3.ErrLocked) && !IsDeadlock(err)
+	}
+	return false
+}
+
+// IsConnectionLost reports whether the connection to the database went away
+func IsConnectionLost(err error) bool {
+	if errors.Is(err, driver.ErrBadConn) || errors.Is(err, mysql.ErrInvalidConn) || errors.Is(err, sql.ErrConnDone) {
+		return true
+	}
+	if n, ok := mysqlNumber(err); ok {
+		switch n {
+		case mysqlServerGone, mysqlServerLost, mysqlServerShutdown, mysqlConnectionKilled, mysqlClientTimeout:
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,139 @@
+package gsdb
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"log/slog"
+	"testing"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestErrorClassifiersMySQL(t *testing.T) {
+
+	testCases := []struct {
+		number   uint16
+		classify func(error) bool
+	}{
+		{1062, IsDuplicateKey},
+		{1586, IsDuplicateKey},
+		{1451, IsForeignKeyViolation},
+		{1452, IsForeignKeyViolation},
+		{1048, IsNotNullViolation},
+		{1364, IsNotNullViolation},
+		{1213, IsDeadlock},
+		{1205, IsLockTimeout},
+		{2006, IsConnectionLost},
+		{2013, IsConnectionLost},
+	}
+
+	classifiers := []func(error) bool{IsDuplicateKey, IsForeignKeyViolation, IsNotNullViolation, IsDeadlock, IsLockTimeout, IsConnectionLost}
+
+	for _, tc := range testCases {
+		t.Run(fmt.Sprintf("%d", tc.number), func(t *testing.T) {
+			err := &Error{Op: "insert", Err: &mysql.MySQLError{Number: tc.number, Message: "test"}}
+			matched := 0
+			for _, classify := range classifiers {
+				if classify(err) {
+					matched++
+				}
+			}
+			assert.True(t, tc.classify(err))
+			assert.Equal(t, 1, matched)
+		})
+	}
+
+	assert.True(t, IsConnectionLost(driver.ErrBadConn))
+	assert.True(t, IsConnectionLost(fmt.Errorf("query: %w", mysql.ErrInvalidConn)))
+	assert.False(t, IsDuplicateKey(errors.New("Duplicate entry")))
+	assert.False(t, IsDuplicateKey(nil))
+}
+
+func TestErrorClassifiersSQLite(t *testing.T) {
+	NewSQLite3("test.db", slog.Default(), context.Background())
+
+	_, err := DB.dbConnection.Exec("PRAGMA foreign_keys = ON;")
+	assert.NoError(t, err)
+	defer DB.dbConnection.Exec("PRAGMA foreign_keys = OFF;")
+
+	for _, statement := range []string{
+		"DROP TABLE IF EXISTS error_child;",
+		"DROP TABLE IF EXISTS error_parent;",
+		"CREATE TABLE error_parent (id INTEGER PRIMARY KEY, email TEXT NOT NULL UNIQUE);",
+		"CREATE TABLE error_child (id INTEGER PRIMARY KEY, parent_id INTEGER NOT NULL REFERENCES error_parent(id));",
+		"INSERT INTO error_parent (id, email) VALUES (1, 'alice@example.com');",
+	} {
+		_, err = DB.dbConnection.Exec(statement)
+		assert.NoError(t, err)
+	}
+
+	_, _, err = DB.Execute("INSERT INTO error_parent (id, email) VALUES (2, 'alice@example.com');")
+	assert.True(t, IsDuplicateKey(err))
+	assert.False(t, IsNotNullViolation(err))
+
+	_, _, err = DB.Execute("INSERT INTO error_parent (id, email) VALUES (1, 'bob@example.com');")
+	assert.True(t, IsDuplicateKey(err))
+
+	_, _, err = DB.Execute("INSERT INTO error_parent (id) VALUES (3);")
+	assert.True(t, IsNotNullViolation(err))
+	assert.False(t, IsDuplicateKey(err))
+
+	_, _, err = DB.Execute("INSERT INTO error_child (id, parent_id) VALUES (1, 9);")
+	assert.True(t, IsForeignKeyViolation(err))
+
+	var dbErr *Error
+	if assert.ErrorAs(t, err, &dbErr) {
+		assert.Equal(t, "insert", dbErr.Op)
+		assert.Equal(t, "error_child", dbErr.Table)
+		assert.Equal(t, "INSERT INTO error_child (id, parent_id) VALUES (1, 9);", dbErr.SQL)
+	}
+}
+
+func TestErrorWrapping(t *testing.T) {
+	m, _ := setupHookMock(t)
+
+	deadlock := &mysql.MySQLError{Number: 1213, Message: "Deadlock found when trying to get lock"}
+	m.ExpectQuery("SELECT id, name FROM Users WHERE id=?").WithArgs(1).WillReturnError(deadlock)
+	m.ExpectExec("UPDATE Users SET name='Bob' WHERE id=1").WillReturnError(&mysql.MySQLError{Number: 2006, Message: "MySQL server has gone away"})
+
+	_, err := DB.Query("SELECT id, name FROM Users WHERE id=?", 1)
+	var dbErr *Error
+	if assert.ErrorAs(t, err, &dbErr) {
+		assert.Equal(t, "select", dbErr.Op)
+		assert.Equal(t, "Users", dbErr.Table)
+		assert.Equal(t, "SELECT id, name FROM Users WHERE id=?", dbErr.SQL)
+	}
+	assert.True(t, IsDeadlock(err))
+	assert.False(t, IsLockTimeout(err))
+	assert.EqualError(t, err, deadlock.Error())
+
+	var me *mysql.MySQLError
+	assert.ErrorAs(t, err, &me)
+
+	_, _, err = DB.Execute("UPDATE Users SET name='Bob' WHERE id=1")
+	assert.True(t, IsConnectionLost(err))
+	if assert.ErrorAs(t, err, &dbErr) {
+		assert.Equal(t, "update", dbErr.Op)
+	}
+	assert.NoError(t, m.ExpectationsWereMet())
+}
+
+func TestErrorSensitiveSQL(t *testing.T) {
+	m, _ := setupHookMock(t)
+
+	m.ExpectExec("INSERT INTO people(name,email,age) VALUES (X'426f62',X'626f62406578616d706c652e636f6d',42);").
+		WillReturnError(&mysql.MySQLError{Number: 1062, Message: "Duplicate entry 'bob@example.com' for key 'email'"})
+
+	_, _, err := DB.Save(SensitivePerson{Name: "Bob", Email: "bob@example.com", Age: 42}, 0)
+	assert.True(t, IsDuplicateKey(err))
+
+	var dbErr *Error
+	if assert.ErrorAs(t, err, &dbErr) {
+		assert.Equal(t, "INSERT INTO people(name,email,age) VALUES (X'426f62','[REDACTED]','[REDACTED]');", dbErr.SQL)
+	}
+	assert.NotContains(t, err.Error(), "bob@example.com")
+	assert.NoError(t, m.ExpectationsWereMet())
+}
@@ -1,27 +1,60 @@
 package gsdb
 
+import (
+	"context"
+	"database/sql"
+)
+
+// executor is what statements run on, either the connection pool or a transaction
+type executor interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+}
+
 func (db *Database) Execute(sql string, parameters ...any) (int64, int64, error) {
+	return db.ExecuteContext(db.context(), sql, parameters...)
+}
+
+// ExecuteContext is Execute with a context, which is passed to the driver and the hooks
+func (db *Database) ExecuteContext(ctx context.Context, sql string, parameters ...any) (int64, int64, error) {
 
-	DatabaseConnection, err := getConnection()
+	DatabaseConnection, release, err := db.acquire(ctx)
 	if err != nil {
 		return 0, 0, err
 	}
+	defer release()
 
-	for k := range db.Counters.Count {
-		db.IncCounter(k)
-	}
+	var lastInsertID, rowsAffected int64
+	err = db.retry(ctx, func(ctx context.Context) error {
+		var err error
+		lastInsertID, rowsAffected, err = db.execute(ctx, DatabaseConnection, sql, parameters...)
+		return err
+	})
+	return lastInsertID, rowsAffected, err
+}
+
+// execute runs a statement on the connection pool or a transaction
+func (db *Database) execute(ctx context.Context, conn executor, sql string, parameters ...any) (int64, int64, error) {
+
+	db.incCounters()
 
-	Result, err := DatabaseConnection.Exec(sql, parameters...)
+	result, err := db.runHooks(ctx, conn, OpExec, sql, parameters, func(ctx context.Context) (HookResult, error) {
+		Result, err := conn.ExecContext(ctx, sql, parameters...)
+		if err != nil {
+			return HookResult{}, db.wrapError(err, sql)
+		}
+
+		LastInsertedID, _ := Result.LastInsertId()
+		RowsAffected, _ := Result.RowsAffected()
+		return HookResult{LastInsertID: LastInsertedID, RowsAffected: RowsAffected}, nil
+	})
 	if err != nil {
 		return 0, 0, err
 	}
 
-	LastInsertedID, _ := Result.LastInsertId()
-	RowsAffected, _ := Result.RowsAffected()
-
 	if ShowSQL {
-		db.Logger.With("lastid", LastInsertedID).With("rows effected", RowsAffected).Info(sql)
+		db.Logger.With("lastid", result.LastInsertID).With("rows effected", result.RowsAffected).Info(db.redact(sql))
 	}
 
-	return LastInsertedID, RowsAffected, nil
+	return result.LastInsertID, result.RowsAffected, nil
 }
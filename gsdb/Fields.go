@@ -1,8 +1,11 @@
 package gsdb
 
 import (
+	"errors"
 	"fmt"
 	l "log/slog"
+	"math"
+	"reflect"
 	"strconv"
 	"time"
 )
@@ -12,6 +15,22 @@ import (
 
 type Field struct {
 	Value any
+
+	db timeReader // the Database the value was read with, whose TimePolicy applies. nil uses DB.
+}
+
+// timeReader applies a TimePolicy to times read from the database. It is implemented by *Database.
+type timeReader interface {
+	readTime(t time.Time) time.Time
+	parseTime(layout string, s string) (time.Time, error)
+}
+
+// reader returns the Database the value was read with, or DB for a Field made by hand
+func (F Field) reader() timeReader {
+	if F.db != nil {
+		return F.db
+	}
+	return DB
 }
 
 func (F Field) AsString() string {
@@ -109,9 +128,9 @@ func (F Field) AsDate(d string) time.Time {
 
 	switch v := F.Value.(type) {
 	case time.Time:
-		return F.Value.(time.Time)
+		return F.reader().readTime(F.Value.(time.Time))
 	case string:
-		t, _ := time.Parse("2006-01-02 15:04:05", F.Value.(string))
+		t, _ := F.reader().parseTime(timeLayout, F.Value.(string))
 		return t
 	default:
 		l.Error("Can not convert type: '" + fmt.Sprintf("%T", v) + "' to a Date")
@@ -125,7 +144,10 @@ func (F Field) AsDateEpoch() int64 {
 	if F.Value == nil {
 		return 0
 	}
-	t, _ := time.Parse("2006-01-02 15:04:05", F.Value.(string))
+	if t, ok := F.Value.(time.Time); ok {
+		return F.reader().readTime(t).Unix()
+	}
+	t, _ := F.reader().parseTime(timeLayout, F.AsString())
 
 	return t.Unix()
 }
@@ -348,3 +370,252 @@ func convToBool[T uint | uint8 | uint16 | uint32 | uint64 | int | int8 | int16 |
 		return false
 	}
 }
+
+// ErrNull is wrapped by the ConversionError returned when a NULL is read with one of the error returning accessors
+var ErrNull = errors.New("value is NULL")
+
+// ConversionError is returned by the error returning Field accessors (Int, String, Time etc.) when the value
+// can't be converted to the type asked for.
+type ConversionError struct {
+	From  string
+	To    string
+	Value any
+	Err   error
+}
+
+func (e *ConversionError) Error() string {
+	msg := fmt.Sprintf("cannot convert %s to %s", e.From, e.To)
+	if e.Value != nil {
+		msg = fmt.Sprintf("cannot convert %s %q to %s", e.From, fmt.Sprint(e.Value), e.To)
+	}
+	if e.Err != nil {
+		msg = msg + ": " + e.Err.Error()
+	}
+	return msg
+}
+
+func (e *ConversionError) Unwrap() error {
+	return e.Err
+}
+
+func (F Field) conversionError(to string, err error) error {
+	if F.Value == nil {
+		return &ConversionError{From: "NULL", To: to, Err: ErrNull}
+	}
+	return &ConversionError{From: fmt.Sprintf("%T", F.Value), To: to, Value: F.Value, Err: err}
+}
+
+// IsNull reports whether the database returned NULL
+func (F Field) IsNull() bool {
+	return F.Value == nil
+}
+
+// Int64 returns the value as an int64. Unlike AsInt64, it returns a *ConversionError for NULL, for strings that
+// aren't whole numbers, for fractional floats and for values that overflow.
+func (F Field) Int64() (int64, error) {
+
+	if F.Value == nil {
+		return 0, F.conversionError("int64", nil)
+	}
+
+	v := reflect.ValueOf(F.Value)
+	switch {
+	case v.Kind() == reflect.Bool:
+		if v.Bool() {
+			return 1, nil
+		}
+		return 0, nil
+	case v.CanInt():
+		return v.Int(), nil
+	case v.CanUint():
+		if v.Uint() > math.MaxInt64 {
+			return 0, F.conversionError("int64", strconv.ErrRange)
+		}
+		return int64(v.Uint()), nil
+	case v.CanFloat():
+		f := v.Float()
+		if f != math.Trunc(f) || f < math.MinInt64 || f >= math.MaxInt64 {
+			return 0, F.conversionError("int64", errors.New("not a whole number in range"))
+		}
+		return int64(f), nil
+	}
+
+	s, ok := F.text()
+	if !ok {
+		return 0, F.conversionError("int64", nil)
+	}
+	i, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		return 0, F.conversionError("int64", err)
+	}
+	return i, nil
+}
+
+// Int returns the value as an int, see Int64
+func (F Field) Int() (int, error) {
+	i, err := F.Int64()
+	if err != nil {
+		return 0, err
+	}
+	if int64(int(i)) != i {
+		return 0, F.conversionError("int", strconv.ErrRange)
+	}
+	return int(i), nil
+}
+
+// Uint64 returns the value as a uint64, with a *ConversionError for NULL, negative numbers and non numbers
+func (F Field) Uint64() (uint64, error) {
+
+	if F.Value == nil {
+		return 0, F.conversionError("uint64", nil)
+	}
+
+	v := reflect.ValueOf(F.Value)
+	if v.CanUint() {
+		return v.Uint(), nil
+	}
+	if s, ok := F.text(); ok {
+		u, err := strconv.ParseUint(s, 10, 64)
+		if err != nil {
+			return 0, F.conversionError("uint64", err)
+		}
+		return u, nil
+	}
+
+	i, err := F.Int64()
+	if err != nil {
+		return 0, F.conversionError("uint64", errors.Unwrap(err))
+	}
+	if i < 0 {
+		return 0, F.conversionError("uint64", strconv.ErrRange)
+	}
+	return uint64(i), nil
+}
+
+// Float64 returns the value as a float64, with a *ConversionError for NULL and non numbers
+func (F Field) Float64() (float64, error) {
+
+	if F.Value == nil {
+		return 0, F.conversionError("float64", nil)
+	}
+
+	v := reflect.ValueOf(F.Value)
+	switch {
+	case v.CanInt():
+		return float64(v.Int()), nil
+	case v.CanUint():
+		return float64(v.Uint()), nil
+	case v.CanFloat():
+		return v.Float(), nil
+	}
+
+	s, ok := F.text()
+	if !ok {
+		return 0, F.conversionError("float64", nil)
+	}
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0, F.conversionError("float64", err)
+	}
+	return f, nil
+}
+
+// Bool returns the value as a bool. Numbers are true when non-zero, strings are parsed with strconv.ParseBool.
+func (F Field) Bool() (bool, error) {
+
+	if F.Value == nil {
+		return false, F.conversionError("bool", nil)
+	}
+
+	v := reflect.ValueOf(F.Value)
+	switch {
+	case v.Kind() == reflect.Bool:
+		return v.Bool(), nil
+	case v.CanInt():
+		return v.Int() != 0, nil
+	case v.CanUint():
+		return v.Uint() != 0, nil
+	case v.CanFloat():
+		return v.Float() != 0, nil
+	}
+
+	s, ok := F.text()
+	if !ok {
+		return false, F.conversionError("bool", nil)
+	}
+	b, err := strconv.ParseBool(s)
+	if err != nil {
+		return false, F.conversionError("bool", err)
+	}
+	return b, nil
+}
+
+// String returns the value as a string. Numbers and bools are formatted, anything else is a *ConversionError.
+func (F Field) String() (string, error) {
+
+	if F.Value == nil {
+		return "", F.conversionError("string", nil)
+	}
+
+	if s, ok := F.text(); ok {
+		return s, nil
+	}
+
+	v := reflect.ValueOf(F.Value)
+	switch {
+	case v.Kind() == reflect.Bool:
+		return strconv.FormatBool(v.Bool()), nil
+	case v.CanInt():
+		return strconv.FormatInt(v.Int(), 10), nil
+	case v.CanUint():
+		return strconv.FormatUint(v.Uint(), 10), nil
+	case v.CanFloat():
+		return strconv.FormatFloat(v.Float(), 'f', -1, 64), nil
+	}
+	return "", F.conversionError("string", nil)
+}
+
+// Bytes returns the value as a []byte, for string and []byte values
+func (F Field) Bytes() ([]byte, error) {
+
+	switch v := F.Value.(type) {
+	case []byte:
+		return v, nil
+	case string:
+		return []byte(v), nil
+	}
+	return nil, F.conversionError("[]byte", nil)
+}
+
+// Time returns the value as a time.Time. Strings are parsed with the layout, or "2006-01-02 15:04:05" when the
+// layout is empty, and the Database TimePolicy is applied.
+func (F Field) Time(layout string) (time.Time, error) {
+
+	if t, ok := F.Value.(time.Time); ok {
+		return F.reader().readTime(t), nil
+	}
+
+	s, ok := F.text()
+	if !ok {
+		return time.Time{}, F.conversionError("time.Time", nil)
+	}
+	if layout == "" {
+		layout = timeLayout
+	}
+	t, err := F.reader().parseTime(layout, s)
+	if err != nil {
+		return time.Time{}, F.conversionError("time.Time", err)
+	}
+	return t, nil
+}
+
+// text returns string and []byte values as a string
+func (F Field) text() (string, bool) {
+	switch v := F.Value.(type) {
+	case string:
+		return v, true
+	case []byte:
+		return string(v), true
+	}
+	return "", false
+}
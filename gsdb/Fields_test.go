@@ -0,0 +1,142 @@
+package gsdb
+
+import (
+	"errors"
+	"math"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFieldIsNull(t *testing.T) {
+	assert.True(t, Field{}.IsNull())
+	assert.False(t, Field{Value: 0}.IsNull())
+}
+
+func TestFieldInt(t *testing.T) {
+	tests := []struct {
+		name     string
+		value    any
+		expected int64
+		err      string
+	}{
+		{"int64", int64(42), 42, ""},
+		{"int32", int32(-7), -7, ""},
+		{"uint8", uint8(200), 200, ""},
+		{"whole float", float64(3), 3, ""},
+		{"bool", true, 1, ""},
+		{"string", "123", 123, ""},
+		{"bytes", []byte("-5"), -5, ""},
+		{"null", nil, 0, "cannot convert NULL to int64: value is NULL"},
+		{"fraction", 3.5, 0, `cannot convert float64 "3.5" to int64: not a whole number in range`},
+		{"text", "abc", 0, `cannot convert string "abc" to int64: strconv.ParseInt: parsing "abc": invalid syntax`},
+		{"overflow", uint64(math.MaxUint64), 0, `cannot convert uint64 "18446744073709551615" to int64: value out of range`},
+		{"time", time.Time{}, 0, `cannot convert time.Time "0001-01-01 00:00:00 +0000 UTC" to int64`},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := Field{Value: tc.value}.Int64()
+			if tc.err != "" {
+				assert.EqualError(t, err, tc.err)
+				var ce *ConversionError
+				assert.ErrorAs(t, err, &ce)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expected, got)
+
+			i, err := Field{Value: tc.value}.Int()
+			assert.NoError(t, err)
+			assert.Equal(t, int(tc.expected), i)
+		})
+	}
+
+	_, err := Field{}.Int()
+	assert.ErrorIs(t, err, ErrNull)
+}
+
+func TestFieldUint64(t *testing.T) {
+	u, err := Field{Value: "18446744073709551615"}.Uint64()
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(math.MaxUint64), u)
+
+	u, err = Field{Value: int64(9)}.Uint64()
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(9), u)
+
+	_, err = Field{Value: int64(-1)}.Uint64()
+	assert.ErrorIs(t, err, strconv.ErrRange)
+}
+
+func TestFieldFloatBool(t *testing.T) {
+	f, err := Field{Value: "1.25"}.Float64()
+	assert.NoError(t, err)
+	assert.Equal(t, 1.25, f)
+
+	f, err = Field{Value: int64(2)}.Float64()
+	assert.NoError(t, err)
+	assert.Equal(t, 2.0, f)
+
+	_, err = Field{Value: "x"}.Float64()
+	assert.Error(t, err)
+
+	for value, expected := range map[any]bool{true: true, int64(0): false, int64(2): true, "1": true, "false": false} {
+		b, err := Field{Value: value}.Bool()
+		assert.NoError(t, err)
+		assert.Equal(t, expected, b, "value %v", value)
+	}
+
+	_, err = Field{Value: "maybe"}.Bool()
+	assert.Error(t, err)
+}
+
+func TestFieldStringBytes(t *testing.T) {
+	for value, expected := range map[any]string{"a": "a", int32(5): "5", uint(6): "6", 1.5: "1.5", true: "true"} {
+		s, err := Field{Value: value}.String()
+		assert.NoError(t, err)
+		assert.Equal(t, expected, s)
+	}
+
+	s, err := Field{Value: []byte("bytes")}.String()
+	assert.NoError(t, err)
+	assert.Equal(t, "bytes", s)
+
+	// AsString panics on these, String returns an error
+	_, err = Field{Value: time.Time{}}.String()
+	assert.EqualError(t, err, `cannot convert time.Time "0001-01-01 00:00:00 +0000 UTC" to string`)
+
+	b, err := Field{Value: "abc"}.Bytes()
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("abc"), b)
+
+	_, err = Field{Value: 1}.Bytes()
+	assert.EqualError(t, err, `cannot convert int "1" to []byte`)
+}
+
+func TestFieldTime(t *testing.T) {
+	expected := time.Date(2025, time.December, 25, 15, 29, 25, 0, time.UTC)
+
+	got, err := Field{Value: "2025-12-25 15:29:25"}.Time("")
+	assert.NoError(t, err)
+	assert.Equal(t, expected, got)
+
+	got, err = Field{Value: []byte("25/12/2025 15:29:25")}.Time("02/01/2006 15:04:05")
+	assert.NoError(t, err)
+	assert.Equal(t, expected, got)
+
+	got, err = Field{Value: expected}.Time("")
+	assert.NoError(t, err)
+	assert.Equal(t, expected, got)
+
+	_, err = Field{Value: "not a date"}.Time("")
+	var ce *ConversionError
+	assert.True(t, errors.As(err, &ce))
+	assert.Equal(t, "string", ce.From)
+	assert.Equal(t, "time.Time", ce.To)
+
+	_, err = Field{}.Time("")
+	assert.ErrorIs(t, err, ErrNull)
+}
@@ -0,0 +1,80 @@
+package gsdb
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+)
+
+// QueryOption changes how the generated SELECT statements behave
+type QueryOption func(*queryOptions)
+
+type queryOptions struct {
+	withDeleted bool
+	preload     []string
+	query       queryFunc
+	db          *Database
+}
+
+// queryFunc runs a query, on the Database or inside a transaction
+type queryFunc func(sql string, parameters ...any) ([]Record, error)
+
+// WithDeleted includes soft deleted rows in the results
+func WithDeleted() QueryOption {
+	return func(o *queryOptions) {
+		o.withDeleted = true
+	}
+}
+
+func buildQueryOptions(options []QueryOption) queryOptions {
+	var o queryOptions
+	for _, option := range options {
+		option(&o)
+	}
+	return o
+}
+
+// splitQueryOptions takes any QueryOption out of the query parameters. A QueryOption can never be a valid
+// parameter, so QueryStruct can accept them alongside the parameters.
+func splitQueryOptions(parameters []any) ([]any, queryOptions) {
+	var options []QueryOption
+	params := make([]any, 0, len(parameters))
+	for _, p := range parameters {
+		if option, ok := p.(QueryOption); ok {
+			options = append(options, option)
+			continue
+		}
+		params = append(params, p)
+	}
+	return params, buildQueryOptions(options)
+}
+
+// FindByPK loads a single row using the primary key of T. Soft deleted rows are not returned unless
+// WithDeleted() is passed, and relations can be loaded with Preload. If there's no matching row, the zero
+// value of T is returned.
+func FindByPK[T any](primaryKeyValue any, options ...QueryOption) (T, error) {
+	return FindByPKContext[T](DB.context(), primaryKeyValue, options...)
+}
+
+// FindByPKContext is FindByPK with a context, which is passed to the driver and the hooks
+func FindByPKContext[T any](ctx context.Context, primaryKeyValue any, options ...QueryOption) (T, error) {
+
+	var st T
+	o := buildQueryOptions(options)
+
+	key, err := DB.getStructKey(st)
+	if err != nil {
+		return st, err
+	}
+
+	sql := fmt.Sprintf("SELECT * FROM %s WHERE %s=?", key.table, key.column)
+	if key.softDeleteField != -1 && !o.withDeleted {
+		sql = sql + " AND " + notDeletedCondition(reflect.TypeOf(st), key)
+	}
+
+	parameters := []any{DB.keyParameter(primaryKeyValue)}
+	for _, option := range options {
+		parameters = append(parameters, option)
+	}
+	return QuerySingleStructContext[T](ctx, sql+";", parameters...)
+}
@@ -0,0 +1,66 @@
+package gsdb
+
+import (
+	"context"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFindByPKSoftDelete(t *testing.T) {
+	NewSQLite3("test.db", slog.Default(), context.Background())
+	setupClockOn(DB)
+
+	_, err := DB.dbConnection.Exec("DROP TABLE IF EXISTS Users;")
+	assert.NoError(t, err)
+	_, err = DB.dbConnection.Exec("CREATE TABLE Users (id INTEGER PRIMARY KEY AUTOINCREMENT, name TEXT, deleted_at DATETIME, deleted BOOLEAN DEFAULT false);")
+	assert.NoError(t, err)
+
+	sql, err := DB.Insert(SoftDeletePerson{Name: "Test"})
+	assert.NoError(t, err)
+	_, _, err = DB.Execute(sql)
+	assert.NoError(t, err)
+
+	found, err := FindByPK[SoftDeletePerson](1)
+	assert.NoError(t, err)
+	assert.Equal(t, "Test", found.Name)
+	assert.True(t, found.Deleted.IsZero())
+
+	sql, err = DB.Delete(found)
+	assert.NoError(t, err)
+	_, _, err = DB.Execute(sql)
+	assert.NoError(t, err)
+
+	found, err = FindByPK[SoftDeletePerson](1)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, found.Id, "soft deleted rows are excluded by default")
+
+	found, err = FindByPK[SoftDeletePerson](1, WithDeleted())
+	assert.NoError(t, err)
+	assert.Equal(t, 1, found.Id)
+	assert.False(t, found.Deleted.IsZero())
+
+	sql, err = DB.Restore(found)
+	assert.NoError(t, err)
+	_, _, err = DB.Execute(sql)
+	assert.NoError(t, err)
+
+	found, err = FindByPK[SoftDeletePerson](1)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, found.Id)
+
+	// bool flag
+	flag, err := FindByPK[SoftDeleteFlagPerson](1)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, flag.Id)
+
+	sql, err = DB.Delete(flag)
+	assert.NoError(t, err)
+	_, _, err = DB.Execute(sql)
+	assert.NoError(t, err)
+
+	flag, err = FindByPK[SoftDeleteFlagPerson](1)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, flag.Id)
+}
@@ -7,10 +7,22 @@ import (
 	"strings"
 )
 
-// Insert generates an SQL query based on the db column tags provided in the structure of the argument
+// Insert generates an SQL query based on the db column tags provided in the structure of the argument.
+// If a pointer is passed, generated keys and autocreate and autoupdate times are written back into the struct.
 func (db *Database) Insert(dbStructure any) (string, error) {
+	dbStructure = structPointer(dbStructure)
+	if err := beforeInsert(dbStructure); err != nil {
+		return "", err
+	}
+	if err := db.applyGeneratedKeys(dbStructure); err != nil {
+		return "", err
+	}
+	dbStructure = db.applyAutoTimestamps(dbStructure, true)
+	if err := db.validate(dbStructure); err != nil {
+		return "", err
+	}
 	t := reflect.TypeOf(dbStructure)
-	table, buildSql, err := generateBuildSql(dbStructure, t)
+	table, buildSql, err := db.generateBuildSql(dbStructure, t)
 	if err != nil {
 		return "", err
 	}
@@ -20,33 +32,57 @@ func (db *Database) Insert(dbStructure any) (string, error) {
 	if buildSql == "" {
 		return "", fmt.Errorf("no non-primary key and non-omitted fields found in structure")
 	}
-	valueSql, err := generateValuesSql(dbStructure, t)
+	valueSql, err := db.generateValuesSql(dbStructure, t)
 	if err != nil {
 		return "", err
 	}
-	return fmt.Sprintf("INSERT INTO %s(%s) VALUES %s;", table, buildSql, valueSql), nil
+	return db.unmark(fmt.Sprintf("INSERT INTO %s(%s) VALUES %s;", table, buildSql, valueSql)), nil
 }
 
-// InsertMany generates an SQL query based on the db column tags provided in the structure of the elements in the argument
+// InsertMany generates an SQL query based on the db column tags provided in the structure of the elements in the argument.
+// The elements can be structs or pointers to structs. Generated keys and autocreate and autoupdate times are written
+// back into the elements, so the caller sees the values that were written.
 func InsertMany[T any](dbStructures []T) (string, error) {
 	if len(dbStructures) == 0 {
 		return "", nil
 	}
-	t := reflect.TypeOf(dbStructures[0])
-	table, buildSql, err := generateBuildSql(dbStructures[0], t)
-	if err != nil {
-		return "", err
-	}
-	if table == "" {
-		return "", fmt.Errorf("no table found in structure")
-	}
-	if buildSql == "" {
-		return "", fmt.Errorf("no non-primary key and non-omitted fields found in structure")
-	}
+	var t reflect.Type
+	var table, buildSql string
 	var valuesSql strings.Builder
 	entriesLength := len(dbStructures)
-	for i, dbStructure := range dbStructures {
-		valueSql, err := generateValuesSql(dbStructure, t)
+	for i := range dbStructures {
+		var entity any = &dbStructures[i]
+		if v := reflect.ValueOf(dbStructures[i]); v.Kind() == reflect.Pointer {
+			if v.IsNil() {
+				return "", fmt.Errorf("element %d is nil", i)
+			}
+			entity = dbStructures[i]
+		}
+		if err := beforeInsert(entity); err != nil {
+			return "", err
+		}
+		if err := DB.applyGeneratedKeys(entity); err != nil {
+			return "", err
+		}
+		entity = DB.applyAutoTimestamps(entity, true)
+		if err := DB.validate(entity); err != nil {
+			return "", err
+		}
+		if t == nil {
+			var err error
+			t = reflect.TypeOf(entity)
+			table, buildSql, err = DB.generateBuildSql(entity, t)
+			if err != nil {
+				return "", err
+			}
+			if table == "" {
+				return "", fmt.Errorf("no table found in structure")
+			}
+			if buildSql == "" {
+				return "", fmt.Errorf("no non-primary key and non-omitted fields found in structure")
+			}
+		}
+		valueSql, err := DB.generateValuesSql(entity, t)
 		if err != nil {
 			return "", err
 		}
@@ -55,5 +91,5 @@ func InsertMany[T any](dbStructures []T) (string, error) {
 			valuesSql.WriteString("\n")
 		}
 	}
-	return fmt.Sprintf("INSERT INTO %s(%s) VALUES %s;", table, buildSql, valuesSql.String()), nil
+	return DB.unmark(fmt.Sprintf("INSERT INTO %s(%s) VALUES %s;", table, buildSql, valuesSql.String())), nil
 }
@@ -98,3 +98,35 @@ func TestInsertReadDefaultNullMatrix(t *testing.T) {
 		})
 	}
 }
+
+func TestInsertReadOnlyWriteOnly(t *testing.T) {
+	type AccessPerson struct {
+		Id       int       `db:"column=id primarykey=yes table=Test"`
+		Name     string    `db:"column=name"`
+		Created  time.Time `db:"column=created readonly=yes"`
+		Password string    `db:"column=password writeonly=yes"`
+	}
+
+	entries := []AccessPerson{
+		{Name: "Test", Created: time.Now(), Password: "secret"},
+		{Name: "Test2", Created: time.Now(), Password: "secret2"},
+	}
+
+	sqlQuery, err := DB.Insert(entries[0])
+	if err != nil {
+		t.Fatalf("failed to generate Insert SQL: %v", err)
+	}
+	expected := "INSERT INTO Test(name,password) VALUES (X'54657374',X'736563726574');"
+	if sqlQuery != expected {
+		t.Errorf("Expected: %s, got: %s", expected, sqlQuery)
+	}
+
+	sqlQuery, err = InsertMany(entries)
+	if err != nil {
+		t.Fatalf("failed to generate InsertMany SQL: %v", err)
+	}
+	expected = "INSERT INTO Test(name,password) VALUES (X'54657374',X'736563726574')\n(X'5465737432',X'73656372657432');"
+	if sqlQuery != expected {
+		t.Errorf("Expected: %s, got: %s", expected, sqlQuery)
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"errors"
 	"log/slog"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	_ "github.com/go-sql-driver/mysql"
@@ -19,10 +20,26 @@ type Database struct {
 	ColumnWarnings             bool
 	Lock                       sync.Mutex
 	connected                  bool
+	driverName                 string
 	MaxDatabaseOpenConnections int
 	MaxDatabaseIdleConnections int
 	DatabaseIdleTimeout        time.Duration
 	Ctx                        context.Context
+	NamingStrategy             NamingStrategy
+	Clock                      func() time.Time
+	TimePolicy                 TimePolicy
+	Hooks                      []Hook
+	SlowQueryThreshold         time.Duration
+	ExplainSlowQueries         bool
+	NPlusOne                   *NPlusOneDetector
+	Tracer                     Tracer
+	Redactor                   Redactor
+	sensitive                  sensitiveStatements
+	Retry                      *RetryPolicy
+	metrics                    metrics
+	health                     healthState
+	closing                    atomic.Bool
+	inFlight                   sync.WaitGroup
 	Counters
 }
 
@@ -56,6 +73,7 @@ func NewSQLite3(fileName string, L *slog.Logger, c context.Context) {
 
 	DB = &Database{
 		connected:    true,
+		driverName:   "sqlite3",
 		dbConnection: db,
 		DSN:          fileName,
 		Logger:       L,
@@ -65,50 +83,193 @@ func NewSQLite3(fileName string, L *slog.Logger, c context.Context) {
 	DB.Counters.Count = make(map[string]int64)
 }
 
-func getConnection() (*sql.DB, error) {
+// sqlite reports whether the Database is SQLite, which stores some types (such as UUIDs) differently to MySQL
+func (db *Database) sqlite() bool {
+	return db != nil && db.driverName == "sqlite3"
+}
+
+// ErrClosed is returned for operations started after Close
+var ErrClosed = errors.New("database is closed")
+
+// driver is the name of the database/sql driver, MySQL unless the Database was made by NewSQLite3
+func (db *Database) driver() string {
+	if db.driverName == "" {
+		return "mysql"
+	}
+	return db.driverName
+}
+
+// acquire returns the connection pool for an operation, which counts as in flight (so Close waits for it) until
+// release is called
+func (db *Database) acquire(ctx context.Context) (conn *sql.DB, release func(), err error) {
+
+	// Counted under the lock, so Close either sees the operation or the operation sees Close
+	db.Lock.Lock()
+	if db.closing.Load() {
+		db.Lock.Unlock()
+		return nil, func() {}, ErrClosed
+	}
+	db.inFlight.Add(1)
+	db.Lock.Unlock()
+
+	conn, err = db.connect(ctx)
+	if err != nil {
+		db.inFlight.Done()
+		return nil, func() {}, err
+	}
+	return conn, db.inFlight.Done, nil
+}
+
+// connect returns the connection pool, opening it the first time. After a failed health check the pool is pinged
+// again before it's used (database/sql redials its connections itself). db.Lock is only held to read and set the
+// fields, never while pinging or waiting, so a database that is down doesn't hold up Health or Close.
+func (db *Database) connect(ctx context.Context) (*sql.DB, error) {
+	conn, _, err := db.connectChecked(ctx)
+	return conn, err
+}
+
+// connectChecked is connect, also reporting whether it pinged the database. The ping is as good as a health
+// check, so its result is recorded for Health, and a reconnect made by any operation shows up straight away.
+func (db *Database) connectChecked(ctx context.Context) (conn *sql.DB, checked bool, err error) {
+
+	if db.closing.Load() {
+		return nil, false, ErrClosed
+	}
+
+	db.Lock.Lock()
+	conn, connected := db.dbConnection, db.connected
+	db.Lock.Unlock()
 
-	DB.Lock.Lock()
 	// check once more - in case a prev goroutine has established a connection
-	if DB.connected && DB.dbConnection != nil {
-		DB.Lock.Unlock()
-		return DB.dbConnection, nil
+	if connected && conn != nil {
+		return conn, false, nil
 	}
 
-	if DB.DSN == "" {
-		return nil, errors.New("empty database dsn")
+	reconnect := conn != nil
+	if !reconnect && db.DSN == "" {
+		return nil, false, errors.New("empty database dsn")
 	}
 
-	var err error
+	var latency time.Duration
 
 	// attempt 3 times to connect, then give up
 	for i := 0; i < 3; i++ {
 
-		DB.dbConnection, err = sql.Open("mysql", DB.DSN)
+		if i > 0 {
+			// wait a short while before trying again
+			timer := time.NewTimer(500 * time.Millisecond)
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				if checked {
+					db.health.record(err, latency)
+				}
+				return nil, checked, err
+			case <-timer.C:
+			}
+		}
 
-		if err == nil {
-			// Open may just validate its arguments without creating a connection to the database.
-			// To verify that the data source name is valid, call Ping.
-			err = DB.dbConnection.Ping()
-			if err == nil {
-				break // connection was fine
+		if conn == nil {
+			if conn, err = db.open(); err != nil {
+				db.Logger.With("attempt", i).With("error", err.Error()).Error("Unable to Open Database")
+				continue
 			}
-			DB.Logger.With("attempt", i).With("error", err.Error()).Error("Unable to Ping Database")
-			time.Sleep(500 * time.Millisecond) // wait a short while before trying again
-			continue
 		}
-		time.Sleep(500 * time.Millisecond)
-		DB.Logger.With("attempt", i).With("error", err.Error()).Error("Unable to Ping Database")
+
+		// Open may just validate its arguments without creating a connection to the database.
+		// To verify that the data source name is valid, call Ping.
+		start := time.Now()
+		err = conn.PingContext(ctx)
+		latency, checked = time.Since(start), true
+		if err == nil {
+			break // connection was fine
+		}
+		db.Logger.With("attempt", i).With("error", err.Error()).Error("Unable to Ping Database")
 	}
 
+	if checked {
+		db.health.record(err, latency)
+	}
+	if err != nil {
+		return nil, checked, err
+	}
+
+	db.Lock.Lock()
+	wasConnected := db.connected
+	db.connected = true
+	db.Lock.Unlock()
+
+	if reconnect && !wasConnected {
+		db.health.reconnected()
+		db.Logger.Info("Reconnected to Database")
+	}
+	return conn, checked, nil
+}
+
+// open creates the connection pool, unless another goroutine already has. sql.Open doesn't connect, so it's quick
+// enough to call with db.Lock held.
+func (db *Database) open() (*sql.DB, error) {
+
+	db.Lock.Lock()
+	defer db.Lock.Unlock()
+
+	if db.dbConnection != nil {
+		return db.dbConnection, nil
+	}
+
+	conn, err := sql.Open(db.driver(), db.DSN)
 	if err != nil {
 		return nil, err
 	}
+	conn.SetMaxOpenConns(25)
+	conn.SetMaxIdleConns(25)
+	conn.SetConnMaxIdleTime(5 * time.Minute)
+	db.dbConnection = conn
+	return conn, nil
+}
 
-	DB.dbConnection.SetMaxOpenConns(25)
-	DB.dbConnection.SetMaxIdleConns(25)
-	DB.dbConnection.SetConnMaxIdleTime(5 * time.Minute)
-	DB.connected = true
-	DB.Lock.Unlock()
+// Close shuts the Database down gracefully: the health check is stopped, new operations return ErrClosed, the
+// operations in flight (including whole WithTx transactions) are waited for, and then the connection pool is
+// closed. A closed Database can't be used again.
+func (db *Database) Close() error {
+	return db.CloseContext(context.Background())
+}
+
+// CloseContext is Close with a context, which limits how long the operations in flight are waited for. If it's
+// done first, the pool is closed anyway (which still waits for the statements running on the server), and the
+// context error is returned.
+func (db *Database) CloseContext(ctx context.Context) error {
+
+	db.Lock.Lock()
+	alreadyClosing := db.closing.Swap(true)
+	db.Lock.Unlock()
+	if alreadyClosing {
+		return nil
+	}
+
+	db.StopHealthCheck()
+
+	drained := make(chan struct{})
+	go func() {
+		db.inFlight.Wait()
+		close(drained)
+	}()
 
-	return DB.dbConnection, nil
+	var err error
+	select {
+	case <-drained:
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+
+	db.Lock.Lock()
+	defer db.Lock.Unlock()
+
+	db.connected = false
+	if db.dbConnection != nil {
+		if closeErr := db.dbConnection.Close(); err == nil {
+			err = closeErr
+		}
+	}
+	return err
 }
@@ -0,0 +1,259 @@
+package gsdb
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// Preload loads the named relation fields of the results, with one query per relation.
+//
+// A slice field tagged hasmany=<table> foreignkey=<column> is filled with the rows of <table> whose <column>
+// matches the primary key of the parent. A struct (or pointer) field tagged belongsto=<table> foreignkey=<column>
+// is set to the row of <table> whose primary key matches the <column> of the parent.
+//
+//	type Order struct {
+//		Id         int         `db:"column=id primarykey=yes table=orders"`
+//		CustomerId int         `db:"column=customer_id"`
+//		Lines      []OrderLine `db:"hasmany=order_lines foreignkey=order_id"`
+//		Customer   Customer    `db:"belongsto=customers foreignkey=customer_id"`
+//	}
+//
+//	orders, err := gsdb.QueryStruct[Order]("SELECT * FROM orders", gsdb.Preload("Lines", "Customer"))
+func Preload(fields ...string) QueryOption {
+	return func(o *queryOptions) {
+		o.preload = append(o.preload, fields...)
+	}
+}
+
+// preloadRelations fills in the relations asked for in the options, on a slice of structs
+func preloadRelations(results reflect.Value, options queryOptions) error {
+
+	if len(options.preload) == 0 || results.Len() == 0 {
+		return nil
+	}
+
+	t := results.Type().Elem()
+	for _, name := range options.preload {
+		field, ok := t.FieldByName(name)
+		if !ok {
+			return fmt.Errorf("preload: no field %s in %s", name, t.Name())
+		}
+		dbStructureMap := decodeTag(field.Tag.Get("db"))
+		if dbStructureMap["foreignkey"] == "" {
+			return fmt.Errorf("preload: no foreignkey specified for field %s", name)
+		}
+
+		var err error
+		switch {
+		case dbStructureMap["hasmany"] != "":
+			err = preloadHasMany(results, field, dbStructureMap, options)
+		case dbStructureMap["belongsto"] != "":
+			err = preloadBelongsTo(results, field, dbStructureMap, options)
+		default:
+			err = fmt.Errorf("preload: field %s is not a hasmany or belongsto relation", name)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func preloadHasMany(results reflect.Value, field reflect.StructField, dbStructureMap map[string]string, options queryOptions) error {
+
+	if field.Type.Kind() != reflect.Slice || field.Type.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("preload: hasmany field %s must be a slice of structs", field.Name)
+	}
+
+	// The parents are matched on their primary key
+	pkIndex := primaryKeyIndex(results.Type().Elem())
+	if pkIndex == -1 {
+		return fmt.Errorf("preload: no primary key set on %s", results.Type().Elem().Name())
+	}
+
+	keys := make([]any, 0, results.Len())
+	for i := 0; i < results.Len(); i++ {
+		keys = append(keys, results.Index(i).Field(pkIndex).Interface())
+	}
+
+	foreignKey := dbStructureMap["foreignkey"]
+	children, err := loadRelated(field.Type.Elem(), dbStructureMap["hasmany"], foreignKey, keys, options)
+	if err != nil {
+		return err
+	}
+
+	grouped := make(map[string]reflect.Value)
+	for _, child := range children {
+		key := recordKey(child.record[foreignKey].Value, results.Type().Elem().Field(pkIndex).Type)
+		if _, ok := grouped[key]; !ok {
+			grouped[key] = reflect.MakeSlice(field.Type, 0, 1)
+		}
+		grouped[key] = reflect.Append(grouped[key], child.value)
+	}
+
+	for i := 0; i < results.Len(); i++ {
+		parent := results.Index(i)
+		lines, ok := grouped[relationKey(parent.Field(pkIndex).Interface())]
+		if !ok {
+			lines = reflect.MakeSlice(field.Type, 0, 0)
+		}
+		parent.FieldByIndex(field.Index).Set(lines)
+	}
+	return nil
+}
+
+func preloadBelongsTo(results reflect.Value, field reflect.StructField, dbStructureMap map[string]string, options queryOptions) error {
+
+	relatedType := field.Type
+	if relatedType.Kind() == reflect.Pointer {
+		relatedType = relatedType.Elem()
+	}
+	if relatedType.Kind() != reflect.Struct {
+		return fmt.Errorf("preload: belongsto field %s must be a struct or a pointer to one", field.Name)
+	}
+
+	// The parents hold the key of the related row in their foreignkey column
+	parentType := results.Type().Elem()
+	fkIndex := -1
+	for i := 0; i < parentType.NumField(); i++ {
+		f := parentType.Field(i)
+		if options.db.columnName(f, decodeTag(f.Tag.Get("db"))) == dbStructureMap["foreignkey"] {
+			fkIndex = i
+			break
+		}
+	}
+	if fkIndex == -1 {
+		return fmt.Errorf("preload: no field for foreignkey %s in %s", dbStructureMap["foreignkey"], parentType.Name())
+	}
+
+	pkIndex := primaryKeyIndex(relatedType)
+	if pkIndex == -1 {
+		return fmt.Errorf("preload: no primary key set on %s", relatedType.Name())
+	}
+	pkField := relatedType.Field(pkIndex)
+	pkColumn := options.db.columnName(pkField, decodeTag(pkField.Tag.Get("db")))
+
+	keys := make([]any, 0, results.Len())
+	for i := 0; i < results.Len(); i++ {
+		keys = append(keys, results.Index(i).Field(fkIndex).Interface())
+	}
+
+	related, err := loadRelated(relatedType, dbStructureMap["belongsto"], pkColumn, keys, options)
+	if err != nil {
+		return err
+	}
+
+	byKey := make(map[string]reflect.Value)
+	for _, r := range related {
+		byKey[relationKey(r.value.Field(pkIndex).Interface())] = r.value
+	}
+
+	for i := 0; i < results.Len(); i++ {
+		parent := results.Index(i)
+		r, ok := byKey[relationKey(parent.Field(fkIndex).Interface())]
+		if !ok {
+			continue
+		}
+		if field.Type.Kind() == reflect.Pointer {
+			p := reflect.New(relatedType)
+			p.Elem().Set(r)
+			r = p
+		}
+		parent.FieldByIndex(field.Index).Set(r)
+	}
+	return nil
+}
+
+type relatedRow struct {
+	record Record
+	value  reflect.Value
+}
+
+// preloadBatchSize is the most keys put in one IN list, to stay well under the parameter limits of the databases
+// (999 on older SQLite)
+const preloadBatchSize = 500
+
+// loadRelated runs SELECT ... WHERE column IN (...) for all the keys, skipping soft deleted rows unless WithDeleted
+// was passed. The keys are split into batches of preloadBatchSize.
+func loadRelated(t reflect.Type, table string, column string, keys []any, options queryOptions) ([]relatedRow, error) {
+
+	keys = options.db.uniqueKeys(keys)
+
+	condition := ""
+	if !options.withDeleted {
+		if key, err := options.db.getStructKey(reflect.New(t).Elem().Interface()); err == nil && key.softDeleteField != -1 {
+			condition = " AND " + notDeletedCondition(t, key)
+		}
+	}
+
+	query := options.query
+	if query == nil {
+		query = options.db.Query
+	}
+
+	rows := make([]relatedRow, 0, len(keys))
+	for start := 0; start < len(keys); start += preloadBatchSize {
+		batch := keys[start:min(start+preloadBatchSize, len(keys))]
+		placeholders := strings.TrimSuffix(strings.Repeat("?,", len(batch)), ",")
+		sql := fmt.Sprintf("SELECT * FROM %s WHERE %s IN (%s)", table, column, placeholders) + condition
+
+		allRecords, err := query(sql+";", batch...)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, record := range allRecords {
+			v := reflect.New(t).Elem()
+			if err := options.db.recordToStruct(record, v, len(rows)); err != nil {
+				return nil, err
+			}
+			rows = append(rows, relatedRow{record: record, value: v})
+		}
+	}
+	return rows, nil
+}
+
+func primaryKeyIndex(t reflect.Type) int {
+	for i := 0; i < t.NumField(); i++ {
+		if decodeTag(t.Field(i).Tag.Get("db"))["primarykey"] == "yes" {
+			return i
+		}
+	}
+	return -1
+}
+
+// relationKey turns a key into a string, so keys read as different types (int vs int64, []byte vs string)
+// still match up
+func relationKey(value any) string {
+	if b, ok := value.([]byte); ok {
+		value = string(b)
+	}
+	return fmt.Sprint(uuidValue(value))
+}
+
+// recordKey is the relationKey of a value read straight from the database, for a key field of keyType. UUIDs
+// come back from MySQL as 16 raw bytes and from SQLite as text, so they're read as a UUID when the field is one.
+func recordKey(value any, keyType reflect.Type) string {
+	if isUUIDType(keyType) {
+		var u UUID
+		if err := u.Scan(value); err == nil {
+			return relationKey(u)
+		}
+	}
+	return relationKey(value)
+}
+
+// uniqueKeys drops repeated keys, encoding the rest as query parameters for the Database
+func (db *Database) uniqueKeys(keys []any) []any {
+	seen := make(map[string]bool)
+	unique := make([]any, 0, len(keys))
+	for _, k := range keys {
+		if seen[relationKey(k)] {
+			continue
+		}
+		seen[relationKey(k)] = true
+		unique = append(unique, db.keyParameter(k))
+	}
+	return unique
+}
@@ -0,0 +1,167 @@
+package gsdb
+
+import (
+	"context"
+	"log/slog"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type PreloadCustomer struct {
+	Id   int    `db:"column=id primarykey=yes table=customers"`
+	Name string `db:"column=name"`
+}
+
+type PreloadLine struct {
+	Id      int       `db:"column=id primarykey=yes table=order_lines"`
+	OrderId int       `db:"column=order_id"`
+	Item    string    `db:"column=item"`
+	Deleted time.Time `db:"column=deleted_at softdelete=yes"`
+}
+
+type PreloadOrder struct {
+	Id         int              `db:"column=id primarykey=yes table=orders"`
+	CustomerId int              `db:"column=customer_id"`
+	Lines      []PreloadLine    `db:"hasmany=order_lines foreignkey=order_id"`
+	Customer   PreloadCustomer  `db:"belongsto=customers foreignkey=customer_id"`
+	CustomerP  *PreloadCustomer `db:"belongsto=customers foreignkey=customer_id"`
+}
+
+func setupPreloadTables(t *testing.T) {
+	NewSQLite3("test.db", slog.Default(), context.Background())
+
+	for _, sql := range []string{
+		"DROP TABLE IF EXISTS customers;",
+		"DROP TABLE IF EXISTS orders;",
+		"DROP TABLE IF EXISTS order_lines;",
+		"CREATE TABLE customers (id INTEGER PRIMARY KEY, name TEXT);",
+		"CREATE TABLE orders (id INTEGER PRIMARY KEY, customer_id INTEGER);",
+		"CREATE TABLE order_lines (id INTEGER PRIMARY KEY, order_id INTEGER, item TEXT, deleted_at DATETIME);",
+		"INSERT INTO customers VALUES (1, 'Alice'), (2, 'Bob');",
+		"INSERT INTO orders VALUES (10, 1), (11, 2), (12, 1);",
+		"INSERT INTO order_lines VALUES (100, 10, 'apple', NULL), (101, 10, 'pear', NULL), (102, 11, 'plum', NULL), (103, 11, 'gone', '2020-01-01 00:00:00');",
+	} {
+		_, err := DB.dbConnection.Exec(sql)
+		assert.NoError(t, err)
+	}
+}
+
+func TestPreload(t *testing.T) {
+	setupPreloadTables(t)
+
+	DB.StartCounter("preload")
+	orders, err := QueryStruct[PreloadOrder]("SELECT * FROM orders WHERE id > ? ORDER BY id", 0, Preload("Lines", "Customer", "CustomerP"))
+	assert.NoError(t, err)
+	assert.Equal(t, int64(4), DB.GetCounter("preload"), "one query for the orders and one for each relation")
+
+	assert.Len(t, orders, 3)
+	assert.Equal(t, []string{"apple", "pear"}, []string{orders[0].Lines[0].Item, orders[0].Lines[1].Item})
+	assert.Len(t, orders[1].Lines, 1, "soft deleted lines are skipped")
+	assert.Equal(t, "plum", orders[1].Lines[0].Item)
+	assert.NotNil(t, orders[2].Lines)
+	assert.Empty(t, orders[2].Lines)
+
+	assert.Equal(t, "Alice", orders[0].Customer.Name)
+	assert.Equal(t, "Bob", orders[1].Customer.Name)
+	assert.Equal(t, "Alice", orders[2].CustomerP.Name)
+
+	order, err := FindByPK[PreloadOrder](11, Preload("Lines"), WithDeleted())
+	assert.NoError(t, err)
+	assert.Len(t, order.Lines, 2)
+	assert.Equal(t, "", order.Customer.Name, "only the asked for relations are loaded")
+}
+
+func TestPreloadBatches(t *testing.T) {
+	setupPreloadTables(t)
+
+	for _, sql := range []string{
+		"DELETE FROM customers;",
+		"DELETE FROM orders;",
+		"INSERT INTO customers WITH RECURSIVE n(i) AS (SELECT 1 UNION ALL SELECT i+1 FROM n WHERE i < 1200) SELECT i, 'customer ' || i FROM n;",
+		"INSERT INTO orders WITH RECURSIVE n(i) AS (SELECT 1 UNION ALL SELECT i+1 FROM n WHERE i < 1200) SELECT 1000+i, i FROM n;",
+	} {
+		_, err := DB.dbConnection.Exec(sql)
+		assert.NoError(t, err)
+	}
+
+	DB.StartCounter("batches")
+	orders, err := QueryStruct[PreloadOrder]("SELECT * FROM orders ORDER BY id", Preload("Customer"))
+	assert.NoError(t, err)
+	assert.Equal(t, int64(4), DB.GetCounter("batches"), "one query for the orders and three batches of customers")
+
+	assert.Len(t, orders, 1200)
+	for _, order := range orders {
+		assert.Equal(t, order.CustomerId, order.Customer.Id)
+	}
+	assert.Equal(t, "customer 1200", orders[1199].Customer.Name)
+}
+
+type PreloadSku struct {
+	Code  string             `db:"column=code primarykey=yes table=skus"`
+	Notes []PreloadSkuNote   `db:"hasmany=sku_notes foreignkey=sku"`
+	Owner PreloadSkuSupplier `db:"belongsto=suppliers foreignkey=supplier"`
+	// The supplier key is 16 characters, the length of a raw UUID
+	Supplier string `db:"column=supplier"`
+}
+
+type PreloadSkuNote struct {
+	Id   int    `db:"column=id primarykey=yes table=sku_notes"`
+	Sku  string `db:"column=sku"`
+	Text string `db:"column=text"`
+}
+
+type PreloadSkuSupplier struct {
+	Code string `db:"column=code primarykey=yes table=suppliers"`
+	Name string `db:"column=name"`
+}
+
+func TestPreloadStringKeys(t *testing.T) {
+	NewSQLite3("test.db", slog.Default(), context.Background())
+
+	for _, sql := range []string{
+		"DROP TABLE IF EXISTS skus;",
+		"DROP TABLE IF EXISTS sku_notes;",
+		"DROP TABLE IF EXISTS suppliers;",
+		"CREATE TABLE skus (code TEXT PRIMARY KEY, supplier TEXT);",
+		"CREATE TABLE sku_notes (id INTEGER PRIMARY KEY, sku TEXT, text TEXT);",
+		"CREATE TABLE suppliers (code TEXT PRIMARY KEY, name TEXT);",
+		"INSERT INTO skus VALUES ('WIDGET-BLUE-0001', 'SUPPLIER-ACME-01');",
+		"INSERT INTO sku_notes VALUES (1, 'WIDGET-BLUE-0001', 'fragile');",
+		"INSERT INTO suppliers VALUES ('SUPPLIER-ACME-01', 'Acme');",
+	} {
+		_, err := DB.dbConnection.Exec(sql)
+		assert.NoError(t, err)
+	}
+
+	// 16 character string keys are matched as strings, not read as UUIDs
+	skus, err := QueryStruct[PreloadSku]("SELECT * FROM skus", Preload("Notes", "Owner"))
+	assert.NoError(t, err)
+	if !assert.Len(t, skus, 1) {
+		return
+	}
+	assert.Len(t, skus[0].Notes, 1)
+	assert.Equal(t, "Acme", skus[0].Owner.Name)
+
+	assert.Equal(t, "WIDGET-BLUE-0001", relationKey("WIDGET-BLUE-0001"))
+	assert.Equal(t, "WIDGET-BLUE-0001", recordKey([]byte("WIDGET-BLUE-0001"), reflect.TypeOf("")))
+	id := UUID{1, 2, 3}
+	assert.Equal(t, id.String(), recordKey(string(id[:]), reflect.TypeOf(id)), "raw MySQL bytes for a UUID key")
+}
+
+func TestPreloadErrors(t *testing.T) {
+	setupPreloadTables(t)
+
+	_, err := QueryStruct[PreloadOrder]("SELECT * FROM orders", Preload("Missing"))
+	assert.EqualError(t, err, "preload: no field Missing in PreloadOrder")
+
+	_, err = QueryStruct[PreloadOrder]("SELECT * FROM orders", Preload("CustomerId"))
+	assert.EqualError(t, err, "preload: no foreignkey specified for field CustomerId")
+
+	// Relations are never written
+	sql, err := DB.Insert(PreloadOrder{CustomerId: 1, Lines: []PreloadLine{{Item: "x"}}})
+	assert.NoError(t, err)
+	assert.Equal(t, "INSERT INTO orders(customer_id) VALUES (1);", sql)
+}
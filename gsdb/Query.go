@@ -1,34 +1,103 @@
 package gsdb
 
 import (
-	"fmt"
-	l "log/slog"
+	"context"
+	"database/sql"
 )
 
+// Row is a single row of a result, with the fields in the same order as the columns in the SELECT.
+// Unlike a Record, columns with the same name (e.g. id from both sides of a join) are kept apart.
+type Row []Field
+
+// Record turns the row into a Record keyed by column name. If a column name appears more than once,
+// the last one wins.
+func (r Row) Record(columns []string) Record {
+	out := Record{}
+	for i, col := range columns {
+		if i < len(r) {
+			out[col] = r[i]
+		}
+	}
+	return out
+}
+
 func (db *Database) Query(sql string, parameters ...any) ([]Record, error) {
+	return db.QueryContext(db.context(), sql, parameters...)
+}
 
-	allRecords := make([]Record, 0)
+// QueryContext is Query with a context, which is passed to the driver and the hooks
+func (db *Database) QueryContext(ctx context.Context, sql string, parameters ...any) ([]Record, error) {
 
-	DatabaseConnection, err := getConnection()
+	rs, err := db.QueryResultContext(ctx, sql, parameters...)
 	if err != nil {
-		return allRecords, err
+		return make([]Record, 0), err
 	}
 
-	for k := range db.Counters.Count {
-		db.IncCounter(k)
+	return rs.Records(), nil
+}
+
+// QueryRows runs the query and returns the column names and the rows in the order the database returned them
+func (db *Database) QueryRows(sql string, parameters ...any) ([]string, []Row, error) {
+
+	rs, err := db.QueryResult(sql, parameters...)
+	return rs.ColumnNames(), rs.Rows, err
+}
+
+// QueryResult runs the query and returns a ResultSet, which keeps the column order and type details
+func (db *Database) QueryResult(sql string, parameters ...any) (ResultSet, error) {
+	return db.QueryResultContext(db.context(), sql, parameters...)
+}
+
+// QueryResultContext is QueryResult with a context, which is passed to the driver and the hooks
+func (db *Database) QueryResultContext(ctx context.Context, sql string, parameters ...any) (ResultSet, error) {
+
+	DatabaseConnection, release, err := db.acquire(ctx)
+	if err != nil {
+		return ResultSet{Columns: make([]Column, 0), Rows: make([]Row, 0)}, err
 	}
+	defer release()
+	if !readOnly(sql) {
+		return db.queryResult(ctx, DatabaseConnection, sql, parameters...)
+	}
+
+	var rs ResultSet
+	err = db.retry(ctx, func(ctx context.Context) error {
+		var err error
+		rs, err = db.queryResult(ctx, DatabaseConnection, sql, parameters...)
+		return err
+	})
+	return rs, err
+}
+
+// queryResult runs a query on the connection pool or a transaction
+func (db *Database) queryResult(ctx context.Context, conn executor, sql string, parameters ...any) (ResultSet, error) {
+
+	rs := ResultSet{Columns: make([]Column, 0), Rows: make([]Row, 0)}
+
+	db.incCounters()
 
-	rows, err := DatabaseConnection.Query(sql, parameters...)
+	_, err := db.runHooks(ctx, conn, OpQuery, sql, parameters, func(ctx context.Context) (HookResult, error) {
+		err := db.scanResult(ctx, conn, &rs, sql, parameters...)
+		return HookResult{RowsReturned: int64(len(rs.Rows))}, db.wrapError(err, sql)
+	})
+	return rs, err
+}
+
+// scanResult runs the query and reads all the rows into rs
+func (db *Database) scanResult(ctx context.Context, conn executor, rs *ResultSet, sql string, parameters ...any) error {
+
+	rows, err := conn.QueryContext(ctx, sql, parameters...)
 
 	if err != nil {
-		return allRecords, err
+		return err
 	}
 	defer rows.Close()
 
 	columns, err := rows.Columns()
 	if err != nil {
-		l.Error(fmt.Sprintf("Error while fetching column names, err: %s\n", err.Error()))
+		return err
 	}
+	rs.Columns = describeColumns(rows, columns)
 	count := len(columns)
 	values := make([]interface{}, count)
 	valuePtrs := make([]interface{}, count)
@@ -39,12 +108,12 @@ func (db *Database) Query(sql string, parameters ...any) ([]Record, error) {
 			valuePtrs[i] = &values[i]
 		}
 		if err := rows.Scan(valuePtrs...); err != nil {
-			l.Error(fmt.Sprintf("Error while scanning in query: %s\n", err.Error()))
+			return err
 		}
 
-		out := Record{}
+		out := make(Row, count)
 
-		for i, col := range columns {
+		for i := range columns {
 			val := values[i]
 
 			// TODO: Implement All the Types!
@@ -53,33 +122,61 @@ func (db *Database) Query(sql string, parameters ...any) ([]Record, error) {
 			switch val.(type) {
 			case uint, uint8, uint16, uint32, uint64, int, int8, int16, int32, int64:
 				// fmt.Printf("Int: %v\n", val)
-				out[col] = Field{Value: val}
+				out[i] = Field{Value: val}
 			case float32, float64:
 				// fmt.Printf("Float64: %v\n", val)
-				out[col] = Field{Value: val}
+				out[i] = Field{Value: val}
 			case bool:
-				out[col] = Field{Value: val}
+				out[i] = Field{Value: val}
 			case string:
-				out[col] = Field{Value: val}
+				out[i] = Field{Value: val}
 
 			case []uint8:
 				b, _ := val.([]byte)
 				// fmt.Printf("String: %s\n", string(b))
 				// l.INFO("Type: %T", val)
-				out[col] = Field{Value: string(b)}
+				out[i] = Field{Value: string(b)}
 
 			case interface{}:
 				// l.ERROR("Unknown Type: %T", val)
 				// If the Record is NULL
-				out[col] = Field{Value: val}
+				out[i] = Field{Value: val}
 
 			default:
 				// l.ERROR("Unknown Type: %T", val)
-				out[col] = Field{Value: val}
+				out[i] = Field{Value: val}
 			}
+			out[i].db = db
 		}
-		allRecords = append(allRecords, out)
+		rs.Rows = append(rs.Rows, out)
 	}
 
-	return allRecords, nil
+	// An error part way through the rows ends the loop early, and is only reported here
+	return rows.Err()
+}
+
+// describeColumns reads the column type details from the driver, falling back to just the names
+func describeColumns(rows *sql.Rows, columns []string) []Column {
+
+	described := make([]Column, 0, len(columns))
+	columnTypes, err := rows.ColumnTypes()
+	if err != nil || len(columnTypes) != len(columns) {
+		for _, name := range columns {
+			described = append(described, Column{Name: name})
+		}
+		return described
+	}
+
+	for _, ct := range columnTypes {
+		c := Column{
+			Name:         ct.Name(),
+			DatabaseType: ct.DatabaseTypeName(),
+			ScanType:     ct.ScanType(),
+		}
+		c.Nullable, c.HasNullable = ct.Nullable()
+		c.Length, c.HasLength = ct.Length()
+		c.Precision, c.Scale, c.HasPrecisionScale = ct.DecimalSize()
+		described = append(described, c)
+	}
+	return described
 }
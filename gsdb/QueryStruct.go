@@ -1,103 +1,261 @@
 package gsdb
 
 import (
+	"context"
+	"database/sql"
 	"fmt"
 	l "log/slog"
 	"reflect"
+	"strings"
 )
 
 // You can't do Method Generic types in Go, so we have to use a function.
 
+// QueryStruct runs the query and maps each row onto a T. Options such as Preload can be passed along with the
+// parameters, they are taken out before the query is run.
 func QueryStruct[T any](sql string, parameters ...any) ([]T, error) {
+	return queryStruct[T](DB, DB.context(), DB.QueryContext, sql, parameters)
+}
+
+// QueryStructContext is QueryStruct with a context, which is passed to the driver and the hooks
+func QueryStructContext[T any](ctx context.Context, sql string, parameters ...any) ([]T, error) {
+	return queryStruct[T](DB, ctx, DB.QueryContext, sql, parameters)
+}
+
+// QueryStructTx is QueryStruct inside a transaction. Relations are preloaded inside the transaction too.
+func QueryStructTx[T any](tx *Tx, sql string, parameters ...any) ([]T, error) {
+	return queryStruct[T](tx.db, tx.ctx, tx.QueryContext, sql, parameters)
+}
+
+func queryStruct[T any](db *Database, ctx context.Context, query func(ctx context.Context, sql string, parameters ...any) ([]Record, error),
+	sql string, parameters []any) (results []T, err error) {
+
+	ctx, span := db.startSpan(ctx, "gsdb.QueryStruct", sql)
+	defer func() {
+		span.SetAttribute("db.rows_returned", int64(len(results)))
+		span.End(err)
+	}()
+
+	parameters, options := splitQueryOptions(parameters)
+	options.db = db
+	options.query = func(sql string, parameters ...any) ([]Record, error) {
+		return query(ctx, sql, parameters...)
+	}
 
 	// First of all, get all the database records, ising the old Record/Field method.
-	allRecords, err := DB.Query(sql, parameters...)
+	allRecords, err := query(ctx, sql, parameters...)
 	if err != nil {
 		return make([]T, 0), err
 	}
 
-	results := make([]T, 0)
+	results = make([]T, 0)
+	t := reflect.TypeOf(results).Elem()
+
+	if ColumnWarnings && len(allRecords) > 0 {
+		for _, col := range db.missingColumns(t, allRecords[0]) {
+			l.With("col", col).Warn("Column missing from result set")
+		}
+	}
 
 	for i, record := range allRecords {
 		var newStructRecord T
 
-		for k, v := range record {
-			// Use Reflection to set the value.
+		if err := db.recordToStruct(record, reflect.ValueOf(&newStructRecord).Elem(), i); err != nil {
+			return make([]T, 0), err
+		}
+
+		results = append(results, newStructRecord)
+	}
+
+	if err := preloadRelations(reflect.ValueOf(results), options); err != nil {
+		return make([]T, 0), err
+	}
+	return results, nil
+}
+
+// recordToStruct sets the fields of newStructRecord, which must be an addressable struct, from the record
+func (db *Database) recordToStruct(record Record, newStructRecord reflect.Value, i int) error {
 
-			structFieldName, dbStructureMap, structFieldType := getStructDetails[T](k)
+	// Columns aliased as prefix.column or prefix__column belong to a nested struct
+	nested := make(map[int]Record)
 
-			// fmt.Println(dbStructureMap)
-			// l.Info(fmt.Sprintf("index:%d Key:%s Value:%v structFieldName:%v structFieldType:%v", i, k, "", structFieldName, structFieldType))
+	for k, v := range record {
+		// Use Reflection to set the value.
 
-			switch structFieldType {
-			case "int", "int8", "int16", "int32", "int64":
-				// l.Info(fmt.Sprintf("Setting Int64 field: %s to %v type: %T", structFieldName, v.Value, v.Value))
+		structFieldName, dbStructureMap, structFieldType := db.getStructDetails(newStructRecord.Type(), k)
 
-				reflect.ValueOf(&newStructRecord).Elem().FieldByName(structFieldName).SetInt(v.AsInt64())
+		if structFieldName == "" {
+			if index, column := nestedField(newStructRecord.Type(), k); index != -1 {
+				if nested[index] == nil {
+					nested[index] = Record{}
+				}
+				nested[index][column] = v
+				continue
+			}
+		}
 
-			case "uint", "uint8", "uint16", "uint32", "uint64":
-				reflect.ValueOf(&newStructRecord).Elem().FieldByName(structFieldName).SetUint(v.AsUInt64())
+		// writeonly columns are never read back, even if the query happens to return them
+		if dbStructureMap["writeonly"] == "yes" {
+			continue
+		}
 
-			case "bool":
-				reflect.ValueOf(&newStructRecord).Elem().FieldByName(structFieldName).SetBool(v.AsBool())
+		// Types that can scan themselves, such as Decimal, are given the value as the driver returned it
+		if structFieldName != "" {
+			if ok, err := scanField(newStructRecord.FieldByName(structFieldName), v.Value); ok {
+				if err != nil {
+					return fmt.Errorf("column %s: %w", k, err)
+				}
+				continue
+			}
+		}
 
-			case "float32", "float64":
-				// l.Info(fmt.Sprintf("Setting flaot64 field: %s to %v", structFieldName, v.Value))
-				reflect.ValueOf(&newStructRecord).Elem().FieldByName(structFieldName).SetFloat(v.AsFloat())
+		// fmt.Println(dbStructureMap)
+		// l.Info(fmt.Sprintf("index:%d Key:%s Value:%v structFieldName:%v structFieldType:%v", i, k, "", structFieldName, structFieldType))
 
-			case "string":
-				// l.Info(fmt.Sprintf("Setting String field: %s to %v", structFieldName, v.Value))
-				reflect.ValueOf(&newStructRecord).Elem().FieldByName(structFieldName).SetString(v.AsString())
+		switch structFieldType {
+		case "int", "int8", "int16", "int32", "int64":
+			// l.Info(fmt.Sprintf("Setting Int64 field: %s to %v type: %T", structFieldName, v.Value, v.Value))
 
-			case "Time":
-				// l.Info(fmt.Sprintf("Setting Time field: %s to %v", structFieldName, v.Value))
+			newStructRecord.FieldByName(structFieldName).SetInt(v.AsInt64())
 
-				// Does the Read Default Exist?
-				// If the time is NULL or EMPTY in the database, you can have the struct return it as
-				// Zero (0001-01-01 00:00:00) or the current time.
-				// The first version of this library, use the current time, and that causes all sorts of
-				// issues I needed to work around,  this has been implemented give you the choice of how
-				// to handle it at the struct level.
+		case "uint", "uint8", "uint16", "uint32", "uint64":
+			newStructRecord.FieldByName(structFieldName).SetUint(v.AsUInt64())
 
-				param := ""
-				if dbStructureMap["readdefault"] == "now" {
-					param = "now"
-				}
+		case "bool":
+			newStructRecord.FieldByName(structFieldName).SetBool(v.AsBool())
 
-				if dbStructureMap["readdefault"] == "zero" {
-					param = "zero"
-				}
+		case "float32", "float64":
+			// l.Info(fmt.Sprintf("Setting flaot64 field: %s to %v", structFieldName, v.Value))
+			newStructRecord.FieldByName(structFieldName).SetFloat(v.AsFloat())
 
-				if dbStructureMap["readdefault"] == "null" {
-					param = "zero"
-				}
+		case "string":
+			// l.Info(fmt.Sprintf("Setting String field: %s to %v", structFieldName, v.Value))
+			newStructRecord.FieldByName(structFieldName).SetString(v.AsString())
 
-				reflect.ValueOf(&newStructRecord).Elem().FieldByName(structFieldName).Set(reflect.ValueOf(v.AsDate(param)))
+		case "Time":
+			// l.Info(fmt.Sprintf("Setting Time field: %s to %v", structFieldName, v.Value))
 
-				// Add Blob Support.
-			case "[]uint8":
-				l.Info(fmt.Sprintf("Setting Blob field: %s to %v", structFieldName, v.Value))
-				reflect.ValueOf(&newStructRecord).Elem().FieldByName(structFieldName).Set(reflect.ValueOf(v.AsByte()))
+			// Does the Read Default Exist?
+			// If the time is NULL or EMPTY in the database, you can have the struct return it as
+			// Zero (0001-01-01 00:00:00) or the current time.
+			// The first version of this library, use the current time, and that causes all sorts of
+			// issues I needed to work around,  this has been implemented give you the choice of how
+			// to handle it at the struct level.
 
-			default:
-				if ColumnWarnings {
-					l.With("col", k).With("index", i).With("structFieldName", structFieldName).With("structFieldType", structFieldType).Warn("Unknown type")
-				}
+			param := ""
+			if dbStructureMap["readdefault"] == "now" {
+				param = "now"
+			}
+
+			if dbStructureMap["readdefault"] == "zero" {
+				param = "zero"
+			}
+
+			if dbStructureMap["readdefault"] == "null" || dbStructureMap["softdelete"] == "yes" {
+				param = "zero"
+			}
+
+			newStructRecord.FieldByName(structFieldName).Set(reflect.ValueOf(v.AsDate(param)))
+
+			// Add Blob Support.
+		case "[]uint8":
+			l.Info(fmt.Sprintf("Setting Blob field: %s to %v", structFieldName, v.Value))
+			newStructRecord.FieldByName(structFieldName).Set(reflect.ValueOf(v.AsByte()))
+
+		default:
+			if ColumnWarnings {
+				l.With("col", k).With("index", i).With("structFieldName", structFieldName).With("structFieldType", structFieldType).Warn("Unknown type")
 			}
 		}
+	}
 
-		results = append(results, newStructRecord)
+	for index, nestedRecord := range nested {
+		field := newStructRecord.Field(index)
+		if field.Kind() == reflect.Pointer {
+			field.Set(reflect.New(field.Type().Elem()))
+			field = field.Elem()
+		}
+		if err := db.recordToStruct(nestedRecord, field, i); err != nil {
+			return err
+		}
 	}
-	return results, nil
+
+	return afterLoad(newStructRecord.Addr().Interface())
+}
+
+// scanField sets a field whose type implements sql.Scanner, or a pointer to one, which is left nil for NULL.
+// [16]byte fields are read as a UUID.
+// ok is false if the field can't scan itself.
+func scanField(field reflect.Value, value any) (ok bool, err error) {
+
+	if field.Type() == reflect.TypeOf([16]byte{}) {
+		return true, field.Addr().Convert(reflect.TypeOf(&UUID{})).Interface().(*UUID).Scan(value)
+	}
+
+	if scanner, ok := field.Addr().Interface().(sql.Scanner); ok {
+		return true, scanner.Scan(value)
+	}
+
+	if field.Kind() != reflect.Pointer {
+		return false, nil
+	}
+	if _, ok := reflect.New(field.Type().Elem()).Interface().(sql.Scanner); !ok {
+		return false, nil
+	}
+	if value == nil {
+		field.Set(reflect.Zero(field.Type()))
+		return true, nil
+	}
+	p := reflect.New(field.Type().Elem())
+	if err := p.Interface().(sql.Scanner).Scan(value); err != nil {
+		return true, err
+	}
+	field.Set(p)
+	return true, nil
+}
+
+// nestedField finds the nested struct field (tagged prefix=) that an aliased column such as customer.name or
+// customer__name belongs to. It returns the field index and the column name within the nested struct, or -1.
+func nestedField(t reflect.Type, column string) (int, string) {
+
+	prefix, rest, found := strings.Cut(column, ".")
+	if !found {
+		prefix, rest, found = strings.Cut(column, "__")
+	}
+	if !found {
+		return -1, ""
+	}
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() || decodeTag(field.Tag.Get("db"))["prefix"] != prefix {
+			continue
+		}
+		if field.Type.Kind() == reflect.Struct ||
+			(field.Type.Kind() == reflect.Pointer && field.Type.Elem().Kind() == reflect.Struct) {
+			return i, rest
+		}
+	}
+	return -1, ""
 }
 
 // You can't do Method Generic types in Go, so we have to use a function.
 
 func QuerySingleStruct[T any](sql string, parameters ...any) (T, error) {
 
-	var SingleResult T
-
 	results, err := QueryStruct[T](sql, parameters...)
+	return firstResult(results, err)
+}
+
+// QuerySingleStructContext is QuerySingleStruct with a context, which is passed to the driver and the hooks
+func QuerySingleStructContext[T any](ctx context.Context, sql string, parameters ...any) (T, error) {
+	return firstResult(QueryStructContext[T](ctx, sql, parameters...))
+}
+
+// firstResult returns the first result, or the zero value of T if there are none
+func firstResult[T any](results []T, err error) (T, error) {
+
+	var SingleResult T
 	if err != nil {
 		return SingleResult, err
 	}
@@ -449,3 +449,85 @@ func TestQueryStructReadDefaultNullMixedRows(t *testing.T) {
 		t.Fatalf("expected second Dtadded %v, got: %v", secondExpected, results[1].Dtadded)
 	}
 }
+
+func TestQueryStructReadOnlyWriteOnly(t *testing.T) {
+	NewSQLite3("test.db", slog.Default(), context.Background())
+
+	type AccessPerson struct {
+		Id       int       `db:"column=id primarykey=yes table=access"`
+		Name     string    `db:"column=name"`
+		Created  time.Time `db:"column=created readonly=yes readdefault=zero"`
+		Password string    `db:"column=password writeonly=yes"`
+	}
+
+	_, err := DB.dbConnection.Exec("DROP TABLE IF EXISTS access;")
+	if err != nil {
+		t.Fatalf("failed to execute drop table prior to tests: %v", err)
+	}
+	_, err = DB.dbConnection.Exec(`CREATE TABLE access (id INTEGER PRIMARY KEY AUTOINCREMENT, name TEXT,
+		created DATETIME DEFAULT '2020-01-02 03:04:05', password TEXT);`)
+	if err != nil {
+		t.Fatalf("failed to execute tableCreate SQL prior to tests: %v", err)
+	}
+
+	insertSQL, err := DB.Insert(AccessPerson{Name: "Test", Created: time.Now(), Password: "secret"})
+	if err != nil {
+		t.Fatalf("failed to generate Insert SQL: %v", err)
+	}
+	if _, _, err = DB.Execute(insertSQL); err != nil {
+		t.Fatalf("failed to execute insert: %v", err)
+	}
+
+	// The database default is kept for the readonly column, and the writeonly column is never read back
+	result, err := QuerySingleStruct[AccessPerson]("SELECT * FROM access")
+	if err != nil {
+		t.Fatalf("QuerySingleStruct failed: %v", err)
+	}
+	expected := AccessPerson{Id: 1, Name: "Test", Created: time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)}
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("mismatch in queried result and expected result:\n got  %+v\n want %+v", result, expected)
+	}
+
+	if missing := DB.missingColumns(reflect.TypeOf(AccessPerson{}), Record{"id": {}, "name": {}}); !reflect.DeepEqual(missing, []string{"created"}) {
+		t.Errorf("expected only created to be reported missing, got %v", missing)
+	}
+}
+
+func TestQueryStructNested(t *testing.T) {
+	setupPreloadTables(t)
+
+	type Address struct {
+		City string `db:"column=city"`
+	}
+	type Customer struct {
+		Id      int      `db:"column=id"`
+		Name    string   `db:"column=name"`
+		Address *Address `db:"prefix=address"`
+	}
+	type OrderWithCustomer struct {
+		Id       int       `db:"column=id primarykey=yes table=orders"`
+		Customer Customer  `db:"prefix=customer"`
+		Billing  *Customer `db:"prefix=billing"`
+	}
+
+	results, err := QueryStruct[OrderWithCustomer](`
+		SELECT o.id, c.id AS "customer.id", c.name AS customer__name, 'Leeds' AS "customer.address.city"
+		FROM orders o JOIN customers c ON c.id = o.customer_id ORDER BY o.id`)
+	if err != nil {
+		t.Fatalf("QueryStruct failed: %v", err)
+	}
+
+	expected := OrderWithCustomer{Id: 10, Customer: Customer{Id: 1, Name: "Alice", Address: &Address{City: "Leeds"}}}
+	if len(results) != 3 || !reflect.DeepEqual(results[0], expected) {
+		t.Errorf("mismatch in queried result and expected result:\n got  %+v\n want %+v", results, expected)
+	}
+	if results[1].Customer.Name != "Bob" || results[1].Billing != nil {
+		t.Errorf("expected Bob without billing, got %+v", results[1])
+	}
+
+	// Nested structs are never written
+	_, err = DB.Insert(expected)
+	if err == nil || err.Error() != "no non-primary key and non-omitted fields found in structure" {
+		t.Errorf("expected the nested struct to be skipped by Insert, got: %v", err)
+	}
+}
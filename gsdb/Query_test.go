@@ -0,0 +1,40 @@
+package gsdb
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQueryRowsDuplicateColumns(t *testing.T) {
+	setupPreloadTables(t)
+
+	sql := `SELECT o.id, c.id, c.name FROM orders o JOIN customers c ON c.id = o.customer_id WHERE o.id = ?`
+
+	columns, rows, err := DB.QueryRows(sql, 11)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"id", "id", "name"}, columns)
+	assert.Len(t, rows, 1)
+	assert.Equal(t, 11, rows[0][0].AsInt())
+	assert.Equal(t, 2, rows[0][1].AsInt())
+	assert.Equal(t, "Bob", rows[0][2].AsString())
+
+	// A Record keeps the last of the duplicates
+	records, err := DB.Query(sql, 11)
+	assert.NoError(t, err)
+	assert.Equal(t, Record{"id": {Value: int64(2), db: DB}, "name": {Value: "Bob", db: DB}}, records[0])
+}
+
+func TestQueryRowError(t *testing.T) {
+	m, _ := setupHookMock(t)
+
+	// An error part way through the rows is returned, not a short result
+	broken := errors.New("connection reset")
+	m.ExpectQuery("SELECT id FROM Users").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1).AddRow(2).RowError(1, broken))
+	_, err := DB.Query("SELECT id FROM Users")
+	assert.ErrorIs(t, err, broken)
+	assert.NoError(t, m.ExpectationsWereMet())
+}
@@ -15,26 +15,30 @@ func (db *Database) RecordUpdate(RecordToUpdate Record, UpdateTable string, Upda
 
 	for key, F := range RecordToUpdate {
 		buildsql = buildsql + key + " = "
+		value, sensitive := recordValue(F)
+		if sensitive {
+			buildsql = buildsql + sensitiveMarker
+		}
 
-		switch v := F.Value.(type) {
+		switch v := value.(type) {
 		case int, int32, int64:
-			buildsql = buildsql + fmt.Sprintf("%v", F.Value) + ","
+			buildsql = buildsql + fmt.Sprintf("%v", value) + ","
 		case float64:
-			buildsql = buildsql + fmt.Sprintf("%v", F.Value) + ","
+			buildsql = buildsql + fmt.Sprintf("%v", value) + ","
 		case string:
-			buildsql = buildsql + hexRepresentation(F.Value.(string)) + ","
+			buildsql = buildsql + hexRepresentation(value.(string)) + ","
 		case time.Time:
-			buildsql = buildsql + fmt.Sprintf("'%s'", F.Value.(time.Time).Format("2006-01-02 15:04:05")) + ","
+			buildsql = buildsql + fmt.Sprintf("'%s'", db.formatTime(value.(time.Time))) + ","
 		default:
-			DB.Logger.Error(fmt.Sprintf("%v is unknown", v))
-			buildsql = buildsql + "'" + F.Value.(string) + "',"
+			db.Logger.Error(fmt.Sprintf("%v is unknown", logValue(v, sensitive)))
+			buildsql = buildsql + "'" + value.(string) + "',"
 		}
 
 	}
 	buildsql = strings.TrimSuffix(buildsql, ",")
 	buildsql = buildsql + " WHERE " + UpdateColumn + " = " + UpdateColumnValue
 
-	_, RowsAffected, err := DB.Execute(buildsql)
+	_, RowsAffected, err := db.Execute(db.unmark(buildsql))
 	if err != nil {
 		return RowsAffected, err
 	}
@@ -49,19 +53,23 @@ func (db *Database) RecordInsert(RecordToInsert Record, InsertTable string) (int
 
 	for key, F := range RecordToInsert {
 		buildsql = buildsql + key + ","
+		value, sensitive := recordValue(F)
+		if sensitive {
+			endsql = endsql + sensitiveMarker
+		}
 
-		switch v := F.Value.(type) {
+		switch v := value.(type) {
 		case int, int32, int64:
-			endsql = endsql + fmt.Sprintf("%v", F.AsInt()) + ","
+			endsql = endsql + fmt.Sprintf("%v", Field{Value: value}.AsInt()) + ","
 		case string:
-			endsql = endsql + hexRepresentation(F.Value.(string)) + ","
+			endsql = endsql + hexRepresentation(value.(string)) + ","
 		case float64:
-			endsql = endsql + fmt.Sprintf("%v", F.Value) + ","
+			endsql = endsql + fmt.Sprintf("%v", value) + ","
 		case time.Time:
-			endsql = endsql + fmt.Sprintf("'%s'", F.Value.(time.Time).Format("2006-01-02 15:04:05")) + ","
+			endsql = endsql + fmt.Sprintf("'%s'", db.formatTime(value.(time.Time))) + ","
 		default:
-			DB.Logger.Error(fmt.Sprintf("%v is unknown", v))
-			endsql = endsql + "'" + F.Value.(string) + "',"
+			db.Logger.Error(fmt.Sprintf("%v is unknown", logValue(v, sensitive)))
+			endsql = endsql + "'" + value.(string) + "',"
 		}
 
 	}
@@ -69,7 +77,7 @@ func (db *Database) RecordInsert(RecordToInsert Record, InsertTable string) (int
 	endsql = strings.TrimSuffix(endsql, ",")
 	buildsql = buildsql + ") VALUES (" + endsql + ");"
 
-	id, _, err := DB.Execute(buildsql)
+	id, _, err := db.Execute(db.unmark(buildsql))
 	if err != nil {
 		return 0, err
 	}
@@ -0,0 +1,54 @@
+package gsdb
+
+import "reflect"
+
+// Column describes a column of a ResultSet, as reported by the driver. The Has* flags say whether the driver
+// knows the matching value, e.g. SQLite reports no length or precision.
+type Column struct {
+	Name              string
+	DatabaseType      string
+	Nullable          bool
+	HasNullable       bool
+	Length            int64
+	HasLength         bool
+	Precision         int64
+	Scale             int64
+	HasPrecisionScale bool
+	ScanType          reflect.Type
+}
+
+// ResultSet is the full result of a query, keeping the columns in SELECT order along with their type details.
+// Each Row holds its Fields in the same order as Columns.
+type ResultSet struct {
+	Columns []Column
+	Rows    []Row
+}
+
+// ColumnNames returns the names of the columns in SELECT order
+func (rs ResultSet) ColumnNames() []string {
+	names := make([]string, 0, len(rs.Columns))
+	for _, c := range rs.Columns {
+		names = append(names, c.Name)
+	}
+	return names
+}
+
+// ColumnIndex returns the position of the first column with the name, or -1
+func (rs ResultSet) ColumnIndex(name string) int {
+	for i, c := range rs.Columns {
+		if c.Name == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// Records converts the ResultSet to the []Record returned by Query
+func (rs ResultSet) Records() []Record {
+	names := rs.ColumnNames()
+	allRecords := make([]Record, 0, len(rs.Rows))
+	for _, row := range rs.Rows {
+		allRecords = append(allRecords, row.Record(names))
+	}
+	return allRecords
+}
@@ -0,0 +1,65 @@
+package gsdb
+
+import (
+	"context"
+	"log/slog"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQueryResult(t *testing.T) {
+	setupPreloadTables(t)
+
+	rs, err := DB.QueryResult("SELECT c.name, o.id, c.id FROM orders o JOIN customers c ON c.id = o.customer_id ORDER BY o.id")
+	assert.NoError(t, err)
+
+	assert.Equal(t, []string{"name", "id", "id"}, rs.ColumnNames())
+	assert.Equal(t, "TEXT", rs.Columns[0].DatabaseType)
+	assert.Equal(t, "INTEGER", rs.Columns[1].DatabaseType)
+	assert.Equal(t, 1, rs.ColumnIndex("id"))
+	assert.Equal(t, -1, rs.ColumnIndex("missing"))
+
+	assert.Len(t, rs.Rows, 3)
+	assert.Equal(t, "Alice", rs.Rows[0][0].AsString())
+	assert.Equal(t, 10, rs.Rows[0][1].AsInt())
+	assert.Equal(t, 1, rs.Rows[0][2].AsInt())
+
+	records := rs.Records()
+	assert.Len(t, records, 3)
+	assert.Equal(t, "Bob", records[1]["name"].AsString())
+}
+
+func TestQueryResultColumnDetails(t *testing.T) {
+	New("test/test", slog.Default(), context.Background())
+	db, m, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	assert.NoError(t, err)
+	DB.dbConnection = db
+	DB.connected = true
+
+	rows := sqlmock.NewRowsWithColumnDefinition(
+		sqlmock.NewColumn("amount").OfType("DECIMAL", "").WithPrecisionAndScale(19, 4).Nullable(true),
+		sqlmock.NewColumn("code").OfType("VARCHAR", "").WithLength(16).Nullable(false),
+	).AddRow("12.3400", "GBP")
+	m.ExpectQuery("SELECT amount, code FROM ledger").WillReturnRows(rows)
+
+	rs, err := DB.QueryResult("SELECT amount, code FROM ledger")
+	assert.NoError(t, err)
+	assert.NoError(t, m.ExpectationsWereMet())
+
+	amount, code := rs.Columns[0], rs.Columns[1]
+	assert.Equal(t, "DECIMAL", amount.DatabaseType)
+	assert.True(t, amount.HasPrecisionScale)
+	assert.Equal(t, int64(19), amount.Precision)
+	assert.Equal(t, int64(4), amount.Scale)
+	assert.True(t, amount.HasNullable)
+	assert.True(t, amount.Nullable)
+
+	assert.Equal(t, "VARCHAR", code.DatabaseType)
+	assert.True(t, code.HasLength)
+	assert.Equal(t, int64(16), code.Length)
+	assert.False(t, code.Nullable)
+
+	assert.Equal(t, Row{{Value: "12.3400", db: DB}, {Value: "GBP", db: DB}}, rs.Rows[0])
+}
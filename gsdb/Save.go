@@ -1,28 +1,101 @@
 package gsdb
 
 import (
+	"context"
 	"errors"
 	"reflect"
 )
 
+// ErrStaleObject is returned by Save when a struct with a version=yes field is updated, but the row has
+// been changed (or deleted) since it was read.
+var ErrStaleObject = errors.New("stale object, the row has been changed since it was read")
+
 // Save takes in a structure and if the primary key value is set to a non-zero value, then it will update the object
-// else it will insert the object into the table (taking in a primary key to reduce reflection overhead)
+// else it will insert the object into the table (taking in a primary key to reduce reflection overhead).
+// Pass a pointer to have generated keys, autocreate and autoupdate times, and the bumped version, written back into
+// the structure. For keys made by the client, such as generate=uuidv7, lastInsertedID is not meaningful.
 func (db *Database) Save(dbStructure any, primaryKeyValue any) (lastInsertedID, rowsAffected int64, err error) {
+	return db.SaveContext(db.context(), dbStructure, primaryKeyValue)
+}
+
+// SaveContext is Save with a context, which is passed to the driver and the hooks
+func (db *Database) SaveContext(ctx context.Context, dbStructure any, primaryKeyValue any) (lastInsertedID, rowsAffected int64, err error) {
+
+	ctx, span := db.startSpan(ctx, "gsdb.Save", "")
+	if v := reflect.Indirect(reflect.ValueOf(dbStructure)); v.IsValid() {
+		if key, keyErr := db.getStructKey(v.Interface()); keyErr == nil {
+			span.SetAttribute("db.sql.table", key.table)
+		}
+	}
+	defer func() {
+		span.SetAttribute("db.rows_affected", rowsAffected)
+		span.End(err)
+	}()
+
 	pkvValue := reflect.ValueOf(primaryKeyValue) // pkv => Primary Key Value
 	if !pkvValue.IsValid() {
 		return 0, 0, errors.New("invalid primary key value")
 	}
+
+	// Work on a pointer, so changes made by the hooks are carried through to the generated SQL
+	dbStructure = structPointer(dbStructure)
+	if err = beforeSave(dbStructure); err != nil {
+		return 0, 0, err
+	}
+
 	var sql string
 	if pkvValue.IsZero() {
-		sql, err = DB.Insert(dbStructure)
+		sql, err = db.Insert(dbStructure)
 		if err != nil {
 			return 0, 0, err
 		}
-	} else {
-		sql, err = DB.Update(dbStructure)
+		lastInsertedID, rowsAffected, err = db.ExecuteContext(ctx, sql)
 		if err != nil {
-			return 0, 0, err
+			return lastInsertedID, rowsAffected, err
 		}
+		afterSave(dbStructure)
+		return lastInsertedID, rowsAffected, nil
+	}
+
+	sql, err = db.Update(dbStructure)
+	if err != nil {
+		return 0, 0, err
+	}
+	lastInsertedID, rowsAffected, err = db.ExecuteContext(ctx, sql)
+	if err != nil {
+		return lastInsertedID, rowsAffected, err
+	}
+
+	if err = checkVersion(dbStructure, rowsAffected); err != nil {
+		return lastInsertedID, rowsAffected, err
+	}
+	afterSave(dbStructure)
+	return lastInsertedID, rowsAffected, nil
+}
+
+// checkVersion is called after an update has run. With optimistic locking, no rows means someone else got
+// there first, otherwise the version in the struct is bumped to match the row (when it's a pointer).
+func checkVersion(dbStructure any, rowsAffected int64) error {
+	v := reflect.Indirect(reflect.ValueOf(dbStructure))
+	if v.Kind() != reflect.Struct {
+		return nil
+	}
+	if i := versionFieldIndex(v.Type()); i != -1 {
+		if rowsAffected == 0 {
+			return ErrStaleObject
+		}
+		if v.Field(i).CanSet() {
+			bumpVersion(v.Field(i))
+		}
+	}
+	return nil
+}
+
+// bumpVersion adds one to an integer version field
+func bumpVersion(v reflect.Value) {
+	if v.CanInt() {
+		v.SetInt(v.Int() + 1)
+	} else if v.CanUint() {
+		v.SetUint(v.Uint() + 1)
 	}
-	return DB.Execute(sql)
 }
@@ -164,7 +164,7 @@ func TestSavePrimaryKeyTypes(t *testing.T) {
 
 		// String
 		{"String Empty", "", `INSERT INTO Users(name,status) VALUES (X'54657374',31);`, true},
-		{"String Non-Empty", "42", `UPDATE Users SET name=X'54657374',status=31 WHERE id=42;`, false},
+		{"String Non-Empty", "42", `UPDATE Users SET name=X'54657374',status=31 WHERE id=CAST(X'3432' AS CHAR);`, false},
 	}
 
 	for _, tc := range testCases {
@@ -249,3 +249,60 @@ func TestSaveUpdateError(t *testing.T) {
 	assert.EqualError(t, err, "dummy error")
 	assert.NoError(t, (*mock).ExpectationsWereMet())
 }
+
+type VersionedPerson struct {
+	Id      int    `db:"column=id primarykey=yes table=Users"`
+	Name    string `db:"column=name"`
+	Version int    `db:"column=version version=yes"`
+}
+
+// TestSaveVersionUpdate tests optimistic locking bumps the version on success
+func TestSaveVersionUpdate(t *testing.T) {
+	mock, expectedExec := setupSaveTestMock(t, `UPDATE Users SET name=X'54657374',version=4 WHERE id=1 AND version=3;`)
+	expectedExec.WillReturnResult(sqlmock.NewResult(0, 1))
+	entry := VersionedPerson{1, "Test", 3}
+	_, rowsAffected, err := DB.Save(&entry, entry.Id)
+	assert.NoError(t, err)
+	assert.NoError(t, (*mock).ExpectationsWereMet())
+	assert.Equal(t, int64(1), rowsAffected)
+	assert.Equal(t, 4, entry.Version)
+}
+
+// TestSaveVersionStale tests a save against a changed row returns ErrStaleObject
+func TestSaveVersionStale(t *testing.T) {
+	mock, expectedExec := setupSaveTestMock(t, `UPDATE Users SET name=X'54657374',version=4 WHERE id=1 AND version=3;`)
+	expectedExec.WillReturnResult(sqlmock.NewResult(0, 0))
+	entry := VersionedPerson{1, "Test", 3}
+	_, _, err := DB.Save(&entry, entry.Id)
+	assert.ErrorIs(t, err, ErrStaleObject)
+	assert.NoError(t, (*mock).ExpectationsWereMet())
+	assert.Equal(t, 3, entry.Version)
+}
+
+// TestSaveVersionNotInteger tests the version field has to be an integer
+func TestSaveVersionNotInteger(t *testing.T) {
+	setupSaveTestMock(t, `UPDATE Users SET name=X'54657374' WHERE id=1;`)
+	entry := struct {
+		Id      int    `db:"column=id primarykey=yes table=Users"`
+		Version string `db:"column=version version=yes"`
+	}{1, "a"}
+	_, _, err := DB.Save(entry, entry.Id)
+	assert.EqualError(t, err, "version field must be an integer, not string")
+}
+
+func TestSaveUsesReceiver(t *testing.T) {
+	mock, expectedExec := setupSaveTestMock(t, `INSERT INTO Users(first_name) VALUES (X'54657374');`)
+	expectedExec.WillReturnResult(sqlmock.NewResult(1, 1))
+	DB.NamingStrategy = SnakeCase
+	saver := DB
+
+	// The statement is built and run by the Database Save is called on, even once DB is replaced
+	New("test/test", slog.Default(), context.Background())
+	entry := struct {
+		Id        int `db:"column=id primarykey=yes table=Users"`
+		FirstName string
+	}{FirstName: "Test"}
+	_, _, err := saver.Save(entry, entry.Id)
+	assert.NoError(t, err)
+	assert.NoError(t, (*mock).ExpectationsWereMet())
+}
@@ -0,0 +1,139 @@
+package gsdb
+
+import (
+	"reflect"
+	"time"
+)
+
+// Tracked wraps an entity along with a snapshot of how it looked when it was loaded, so only the columns
+// that have changed are written back by SaveChanges.
+type Tracked[T any] struct {
+	Entity   T
+	snapshot T
+	db       *Database // the Database the changes are written with
+}
+
+// Track starts tracking changes to an entity, using its current state as the snapshot
+func Track[T any](entity T) *Tracked[T] {
+	return &Tracked[T]{Entity: entity, snapshot: snapshotOf(entity), db: DB}
+}
+
+// snapshotOf copies an entity, including the contents of its slice fields (such as []byte), so changes made to
+// them in place show up as changes
+func snapshotOf[T any](entity T) T {
+
+	snapshot := entity
+	v := reflect.ValueOf(&snapshot).Elem()
+	if v.Kind() != reflect.Struct {
+		return snapshot
+	}
+
+	for i := 0; i < v.NumField(); i++ {
+		field := v.Field(i)
+		if field.Kind() != reflect.Slice || field.IsNil() || !field.CanSet() {
+			continue
+		}
+		copied := reflect.MakeSlice(field.Type(), field.Len(), field.Len())
+		reflect.Copy(copied, field)
+		field.Set(copied)
+	}
+	return snapshot
+}
+
+// database is the Database the Tracked was made with, DB for one made directly
+func (tr *Tracked[T]) database() *Database {
+	if tr.db == nil {
+		return DB
+	}
+	return tr.db
+}
+
+// QueryTracked works the same as QueryStruct, but each result is wrapped in a Tracked
+func QueryTracked[T any](sql string, parameters ...any) ([]*Tracked[T], error) {
+
+	results, err := QueryStruct[T](sql, parameters...)
+	if err != nil {
+		return make([]*Tracked[T], 0), err
+	}
+
+	tracked := make([]*Tracked[T], 0, len(results))
+	for _, result := range results {
+		tracked = append(tracked, Track(result))
+	}
+	return tracked, nil
+}
+
+// Changed returns the columns that differ from the snapshot. Only columns that are written by Update are checked,
+// and autoupdate and version columns are left out as gsdb sets those itself.
+func (tr *Tracked[T]) Changed() []string {
+
+	current := reflect.ValueOf(tr.Entity)
+	original := reflect.ValueOf(tr.snapshot)
+	changed := make([]string, 0)
+
+	if current.Kind() != reflect.Struct {
+		return changed
+	}
+
+	t := current.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		dbStructureMap := decodeTag(field.Tag.Get("db"))
+		column := tr.database().columnName(field, dbStructureMap)
+
+		if column == "" || !field.IsExported() || !writableColumn(dbStructureMap) ||
+			dbStructureMap["version"] == "yes" || dbStructureMap["autoupdate"] == "yes" {
+			continue
+		}
+		if !sameValue(current.Field(i).Interface(), original.Field(i).Interface()) {
+			changed = append(changed, column)
+		}
+	}
+	return changed
+}
+
+// SaveChanges writes the changed columns back to the database, and then takes a new snapshot.
+// If nothing has changed, no statement is run.
+func (tr *Tracked[T]) SaveChanges() (rowsAffected int64, err error) {
+
+	if err = beforeSave(&tr.Entity); err != nil {
+		return 0, err
+	}
+
+	changed := tr.Changed()
+	if len(changed) == 0 {
+		return 0, nil
+	}
+
+	columns := make(map[string]bool)
+	for _, column := range changed {
+		columns[column] = true
+	}
+
+	sql, err := tr.database().generateUpdateSql(&tr.Entity, columns)
+	if err != nil {
+		return 0, err
+	}
+
+	_, rowsAffected, err = tr.database().Execute(sql)
+	if err != nil {
+		return rowsAffected, err
+	}
+
+	if err = checkVersion(&tr.Entity, rowsAffected); err != nil {
+		return rowsAffected, err
+	}
+
+	tr.snapshot = snapshotOf(tr.Entity)
+	afterSave(&tr.Entity)
+	return rowsAffected, nil
+}
+
+// sameValue compares two field values, times are compared by instant rather than by location
+func sameValue(a, b any) bool {
+	if at, ok := a.(time.Time); ok {
+		bt, _ := b.(time.Time)
+		return at.Equal(bt)
+	}
+	return reflect.DeepEqual(a, b)
+}
@@ -0,0 +1,142 @@
+package gsdb
+
+import (
+	"context"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type TrackedPerson struct {
+	Id      int       `db:"column=id primarykey=yes table=Users"`
+	Name    string    `db:"column=name"`
+	Status  int       `db:"column=status"`
+	Updated time.Time `db:"column=updated autoupdate=yes"`
+	Version int       `db:"column=version version=yes"`
+}
+
+func TestTrackedSaveChanges(t *testing.T) {
+	NewSQLite3("test.db", slog.Default(), context.Background())
+	now := setupClockOn(DB)
+
+	_, err := DB.dbConnection.Exec("DROP TABLE IF EXISTS Users;")
+	assert.NoError(t, err)
+	_, err = DB.dbConnection.Exec("CREATE TABLE Users (id INTEGER PRIMARY KEY AUTOINCREMENT, name TEXT, status INTEGER, updated DATETIME, version INTEGER);")
+	assert.NoError(t, err)
+	_, err = DB.dbConnection.Exec("INSERT INTO Users (name, status, updated, version) VALUES ('Test', 1, '2020-01-01 00:00:00', 1);")
+	assert.NoError(t, err)
+
+	people, err := QueryTracked[TrackedPerson]("SELECT * FROM Users")
+	assert.NoError(t, err)
+	assert.Len(t, people, 1)
+	p := people[0]
+
+	// Nothing changed, so nothing is run
+	DB.StartCounter("tracked")
+	rows, err := p.SaveChanges()
+	assert.NoError(t, err)
+	assert.Equal(t, int64(0), rows)
+	assert.Equal(t, int64(0), DB.GetCounter("tracked"))
+
+	// Someone else changes the status behind our back, it must not be overwritten
+	_, err = DB.dbConnection.Exec("UPDATE Users SET status=5 WHERE id=1;")
+	assert.NoError(t, err)
+
+	p.Entity.Name = "Changed"
+	assert.Equal(t, []string{"name"}, p.Changed())
+
+	sql, err := DB.generateUpdateSql(p.Entity, map[string]bool{"name": true})
+	assert.NoError(t, err)
+	assert.Equal(t, "UPDATE Users SET name=X'4368616e676564',updated='2025-06-01 12:30:45',version=2 WHERE id=1 AND version=1;", sql)
+
+	rows, err = p.SaveChanges()
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), rows)
+	assert.Equal(t, 2, p.Entity.Version)
+	assert.Equal(t, now, p.Entity.Updated)
+	assert.Empty(t, p.Changed())
+
+	reloaded, err := FindByPK[TrackedPerson](1)
+	assert.NoError(t, err)
+	assert.Equal(t, "Changed", reloaded.Name)
+	assert.Equal(t, 5, reloaded.Status)
+	assert.Equal(t, 2, reloaded.Version)
+
+	// A stale version is reported and the snapshot is kept
+	stale := Track(TrackedPerson{Id: 1, Name: "Test", Status: 5, Version: 1})
+	stale.Entity.Status = 6
+	_, err = stale.SaveChanges()
+	assert.ErrorIs(t, err, ErrStaleObject)
+	assert.Equal(t, []string{"status"}, stale.Changed())
+}
+
+type TrackedDocument struct {
+	Id   int    `db:"column=id primarykey=yes table=documents"`
+	Data []byte `db:"column=data"`
+}
+
+func TestTrackedSliceChangedInPlace(t *testing.T) {
+	New("test/test", slog.Default(), context.Background())
+
+	doc := Track(TrackedDocument{Id: 1, Data: []byte("abc")})
+	assert.Empty(t, doc.Changed())
+
+	doc.Entity.Data[0] = 'x'
+	assert.Equal(t, []string{"data"}, doc.Changed())
+}
+
+func TestTrackedSaveChangesBytes(t *testing.T) {
+	NewSQLite3("test.db", slog.Default(), context.Background())
+
+	for _, statement := range []string{
+		"DROP TABLE IF EXISTS documents;",
+		"CREATE TABLE documents (id INTEGER PRIMARY KEY AUTOINCREMENT, data BLOB);",
+	} {
+		_, err := DB.dbConnection.Exec(statement)
+		assert.NoError(t, err)
+	}
+
+	sql, err := DB.Insert(TrackedDocument{Data: []byte("abc")})
+	assert.NoError(t, err)
+	assert.Equal(t, "INSERT INTO documents(data) VALUES (X'616263');", sql)
+	_, _, err = DB.Execute(sql)
+	assert.NoError(t, err)
+
+	docs, err := QueryTracked[TrackedDocument]("SELECT * FROM documents WHERE id=?", 1)
+	assert.NoError(t, err)
+	if !assert.Len(t, docs, 1) {
+		return
+	}
+
+	docs[0].Entity.Data[0] = 'x'
+	rows, err := docs[0].SaveChanges()
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), rows)
+	assert.Empty(t, docs[0].Changed())
+
+	saved, err := QuerySingleStruct[TrackedDocument]("SELECT * FROM documents WHERE id=?", 1)
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("xbc"), saved.Data)
+
+	// A nil slice is written as NULL
+	sql, err = DB.Update(TrackedDocument{Id: 1})
+	assert.NoError(t, err)
+	assert.Equal(t, "UPDATE documents SET data=NULL WHERE id=1;", sql)
+}
+
+func TestTrackedKeepsDatabase(t *testing.T) {
+	New("test/test", slog.Default(), context.Background())
+	DB.NamingStrategy = SnakeCase
+	person := Track(NamingPerson{Id: 1, FirstName: "Test"})
+
+	// The changes are found and written with the Database the entity was tracked with, even once DB is replaced
+	New("test/test", slog.Default(), context.Background())
+	person.Entity.FirstName = "Changed"
+	assert.Equal(t, []string{"first_name"}, person.Changed())
+
+	direct := &Tracked[NamingPerson]{Entity: NamingPerson{Id: 1}}
+	direct.Entity.CustomerID = 3
+	assert.Empty(t, direct.Changed(), "DB has no NamingStrategy, so the untagged fields aren't columns")
+}
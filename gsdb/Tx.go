@@ -0,0 +1,115 @@
+package gsdb
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+)
+
+// Tx is a transaction started by WithTx. Statements run through it are seen by the hooks, like any other.
+type Tx struct {
+	db  *Database
+	tx  *sql.Tx
+	ctx context.Context
+}
+
+// WithTx runs fn inside a transaction. The transaction is committed if fn returns nil, and rolled back if it returns
+// an error (which is passed back) or panics. The BEGIN, COMMIT and ROLLBACK are passed to the hooks. If the
+// Database has a RetryPolicy and the transaction fails with an error it retries, such as a deadlock, the
+// transaction is rolled back and fn is run again in a new one.
+//
+//	err := gsdb.DB.WithTx(ctx, func(tx *gsdb.Tx) error {
+//		sql, err := gsdb.DB.Insert(order)
+//		if err != nil {
+//			return err
+//		}
+//		_, _, err = tx.Execute(sql)
+//		return err
+//	})
+func (db *Database) WithTx(ctx context.Context, fn func(tx *Tx) error) (err error) {
+
+	ctx, span := db.startSpan(ctx, "gsdb.Tx", "")
+	defer func() {
+		// A panic rolls the transaction back, so the span is ended as failed before the panic carries on
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+			span.End(err)
+			panic(r)
+		}
+		span.End(err)
+	}()
+
+	DatabaseConnection, release, err := db.acquire(ctx)
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	return db.retry(ctx, func(ctx context.Context) error {
+		return db.runTx(ctx, DatabaseConnection, fn)
+	})
+}
+
+// runTx makes a single attempt at the transaction
+func (db *Database) runTx(ctx context.Context, DatabaseConnection *sql.DB, fn func(tx *Tx) error) (err error) {
+
+	tx := &Tx{db: db, ctx: ctx}
+	_, err = db.runHooks(ctx, nil, OpBegin, "BEGIN", nil, func(ctx context.Context) (HookResult, error) {
+		var err error
+		tx.tx, err = DatabaseConnection.BeginTx(ctx, nil)
+		return HookResult{}, db.wrapError(err, "BEGIN")
+	})
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			tx.rollback()
+			panic(r)
+		}
+	}()
+
+	if err = fn(tx); err != nil {
+		if rollbackErr := tx.rollback(); rollbackErr != nil {
+			return fmt.Errorf("%w (rollback failed: %v)", err, rollbackErr)
+		}
+		return err
+	}
+
+	_, err = db.runHooks(ctx, nil, OpCommit, "COMMIT", nil, func(ctx context.Context) (HookResult, error) {
+		return HookResult{}, db.wrapError(tx.tx.Commit(), "COMMIT")
+	})
+	return err
+}
+
+func (tx *Tx) rollback() error {
+	_, err := tx.db.runHooks(tx.ctx, nil, OpRollback, "ROLLBACK", nil, func(ctx context.Context) (HookResult, error) {
+		return HookResult{}, tx.db.wrapError(tx.tx.Rollback(), "ROLLBACK")
+	})
+	return err
+}
+
+// Execute runs a statement inside the transaction, like Database.Execute
+func (tx *Tx) Execute(sql string, parameters ...any) (int64, int64, error) {
+	return tx.db.execute(tx.ctx, tx.tx, sql, parameters...)
+}
+
+// Query runs a query inside the transaction, like Database.Query
+func (tx *Tx) Query(sql string, parameters ...any) ([]Record, error) {
+	return tx.QueryContext(tx.ctx, sql, parameters...)
+}
+
+// QueryContext is Query with a context, which should be the one passed to WithTx or derived from it
+func (tx *Tx) QueryContext(ctx context.Context, sql string, parameters ...any) ([]Record, error) {
+	rs, err := tx.db.queryResult(ctx, tx.tx, sql, parameters...)
+	if err != nil {
+		return make([]Record, 0), err
+	}
+	return rs.Records(), nil
+}
+
+// QueryResult runs a query inside the transaction, like Database.QueryResult
+func (tx *Tx) QueryResult(sql string, parameters ...any) (ResultSet, error) {
+	return tx.db.queryResult(tx.ctx, tx.tx, sql, parameters...)
+}
@@ -8,40 +8,96 @@ import (
 	"time"
 )
 
+// Update generates an UPDATE statement for the structure, using the primarykey=yes field in the WHERE clause.
+// If a pointer is passed, autoupdate times are written back into the struct.
 func (db *Database) Update(dbStructure any) (string, error) {
+	return db.generateUpdateSql(dbStructure, nil)
+}
+
+// generateUpdateSql builds the UPDATE statement. If columns is not nil, only those columns are set, along with
+// any autoupdate and version columns.
+func (db *Database) generateUpdateSql(dbStructure any, columns map[string]bool) (string, error) {
 
+	dbStructure = structPointer(dbStructure)
+	if err := beforeUpdate(dbStructure); err != nil {
+		return "", err
+	}
+	dbStructure = db.applyAutoTimestamps(dbStructure, false)
+	if err := db.validate(dbStructure); err != nil {
+		return "", err
+	}
 	t := reflect.TypeOf(dbStructure)
 	UpdateTable := ""
 	buildsql := ""
 	UpdateColumn := ""
 	UpdateValue := ""
+	VersionCondition := ""
 
 	for i := 0; i < t.NumField(); i++ {
 		field := t.Field(i)
 		tag := field.Tag.Get("db")
 		dbStructureMap := decodeTag(tag)
 
-		if reflect.ValueOf(dbStructure).Field(i).CanInterface() {
+		if reflect.ValueOf(dbStructure).Field(i).CanInterface() && isColumn(dbStructureMap) {
 			value := reflect.ValueOf(dbStructure).Field(i).Interface()
 			// l.INFO("%d. Value='%v'  %v (%v), tag: '%v'\n", i+1, value, field.Name, field.Type.Name(), tag)
 
-			// TODO: Need to look at way for this to happen and not though an error
-			if dbStructureMap["column"] == "" {
+			column := db.columnName(field, dbStructureMap)
+			if column == "" {
 				return "", errors.New("no column name specified for field " + field.Name)
 			}
 
 			if dbStructureMap["primarykey"] == "yes" {
 				// l.INFO("Primary Key Found: %s", dbStructureMap["table"])
-				UpdateColumn = dbStructureMap["column"]
-				UpdateValue = fmt.Sprintf("%v", value)
+				UpdateColumn = column
+				var err error
+				if UpdateValue, err = db.keyLiteral(value); err != nil {
+					return "", err
+				}
 			}
 
 			if dbStructureMap["table"] != "" {
 				UpdateTable = dbStructureMap["table"]
 			}
 
-			if dbStructureMap["omit"] != "yes" && dbStructureMap["primarykey"] != "yes" {
-				buildsql = buildsql + dbStructureMap["column"] + "="
+			// The version column is only changed by gsdb, it's bumped by one and checked in the where clause
+			if dbStructureMap["version"] == "yes" {
+				version, err := versionValue(reflect.ValueOf(dbStructure).Field(i))
+				if err != nil {
+					return "", err
+				}
+				buildsql = buildsql + fmt.Sprintf("%s=%d,", column, version+1)
+				VersionCondition = fmt.Sprintf(" AND %s=%d", column, version)
+				continue
+			}
+
+			// The soft delete column is only changed by Delete and Restore, so saving a struct can't bring a deleted
+			// row back
+			if dbStructureMap["softdelete"] == "yes" {
+				continue
+			}
+
+			if columns != nil && !columns[column] && dbStructureMap["autoupdate"] != "yes" {
+				continue
+			}
+
+			if writableColumn(dbStructureMap) {
+				buildsql = buildsql + column + "="
+				if dbStructureMap["sensitive"] == "yes" {
+					buildsql = buildsql + sensitiveMarker
+				}
+
+				if literal, ok, err := db.valuerLiteral(value); ok {
+					if err != nil {
+						return "", err
+					}
+					buildsql = buildsql + literal + ","
+					continue
+				}
+				if b, ok := value.([]byte); ok {
+					buildsql = buildsql + bytesLiteral(b) + ","
+					continue
+				}
 
 				switch field.Type.Name() {
 				case "uint", "uint8", "uint16", "uint32", "uint64", "int8", "int16", "int", "int32", "int64":
@@ -54,15 +110,15 @@ func (db *Database) Update(dbStructure any) (string, error) {
 					buildsql = buildsql + fmt.Sprintf("%v", value) + ","
 				case "Time":
 					timeValue := value.(time.Time)
-					// If the model uses readdefault=null and is zero time,
+					// If the model uses readdefault=null (or softdelete=yes) and is zero time,
 					// persist SQL NULL instead of a zero-date timestamp.
-					if dbStructureMap["readdefault"] == "null" && timeValue.IsZero() {
+					if nullWhenZero(dbStructureMap) && timeValue.IsZero() {
 						buildsql = buildsql + "NULL,"
 					} else {
-						buildsql = buildsql + fmt.Sprintf("'%s'", timeValue.Format("2006-01-02 15:04:05")) + ","
+						buildsql = buildsql + fmt.Sprintf("'%s'", db.formatTime(timeValue)) + ","
 					}
 				default:
-					db.Logger.With("type", field.Type.Name()).With("value", value).Error("type error")
+					db.Logger.With("type", field.Type.Name()).With("value", logValue(value, dbStructureMap["sensitive"] == "yes")).Error("type error")
 					buildsql = buildsql + "'" + value.(string) + "',"
 				}
 			}
@@ -70,6 +126,7 @@ func (db *Database) Update(dbStructure any) (string, error) {
 	}
 	// Get Rid of Trailing Comma
 
+	UpdateTable = tableName(dbStructure, UpdateTable)
 	if UpdateTable == "" {
 		return "", fmt.Errorf("no table found in structure")
 	}
@@ -83,7 +140,29 @@ func (db *Database) Update(dbStructure any) (string, error) {
 	}
 
 	buildsql = strings.TrimSuffix(buildsql, ",")
-	SQL := "UPDATE " + UpdateTable + " SET " + buildsql + " WHERE " + UpdateColumn + "=" + UpdateValue + ";"
+	SQL := "UPDATE " + UpdateTable + " SET " + buildsql + " WHERE " + UpdateColumn + "=" + UpdateValue + VersionCondition + ";"
+
+	return db.unmark(SQL), nil
+}
 
-	return SQL, nil
+// versionValue reads the value of a version=yes field, which must be an integer
+func versionValue(v reflect.Value) (int64, error) {
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int(), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return int64(v.Uint()), nil
+	default:
+		return 0, fmt.Errorf("version field must be an integer, not %s", v.Type())
+	}
+}
+
+// versionFieldIndex returns the index of the version=yes field in the struct, or -1 if there isn't one
+func versionFieldIndex(t reflect.Type) int {
+	for i := 0; i < t.NumField(); i++ {
+		if decodeTag(t.Field(i).Tag.Get("db"))["version"] == "yes" {
+			return i
+		}
+	}
+	return -1
 }
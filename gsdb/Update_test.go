@@ -230,3 +230,16 @@ func TestNoFieldsUpdate(t *testing.T) {
 	assert.EqualError(t, err, "no non-primary key and non-omitted fields found in structure")
 	assert.Empty(t, sql)
 }
+
+func TestUpdateReadOnlyWriteOnly(t *testing.T) {
+	entry := struct {
+		Id       int       `db:"column=id primarykey=yes table=Users"`
+		Name     string    `db:"column=name"`
+		Created  time.Time `db:"column=created readonly=yes"`
+		Password string    `db:"column=password writeonly=yes"`
+	}{1, "Test", time.Now(), "secret"}
+
+	sql, err := DB.Update(entry)
+	assert.NoError(t, err)
+	assert.Equal(t, "UPDATE Users SET name=X'54657374',password=X'736563726574' WHERE id=1;", sql)
+}
@@ -11,7 +11,7 @@ import (
 )
 
 // generateBuildSql creates the part of the insert SQL query which specifies which columns are to be inserted
-func generateBuildSql(dbStructure any, t reflect.Type) (table string, buildSql string, err error) {
+func (db *Database) generateBuildSql(dbStructure any, t reflect.Type) (table string, buildSql string, err error) {
 	var sb strings.Builder
 
 	for i := 0; i < t.NumField(); i++ {
@@ -19,9 +19,10 @@ func generateBuildSql(dbStructure any, t reflect.Type) (table string, buildSql s
 		tag := field.Tag.Get("db")
 		dbStructureMap := decodeTag(tag)
 
-		if reflect.ValueOf(dbStructure).Field(i).CanInterface() {
+		if reflect.ValueOf(dbStructure).Field(i).CanInterface() && isColumn(dbStructureMap) {
 
-			if dbStructureMap["column"] == "" {
+			column := db.columnName(field, dbStructureMap)
+			if column == "" {
 				return "", "", errors.New("no column name specified for field " + field.Name)
 			}
 
@@ -29,34 +30,50 @@ func generateBuildSql(dbStructure any, t reflect.Type) (table string, buildSql s
 				table = dbStructureMap["table"]
 			}
 
-			if dbStructureMap["omit"] != "yes" && dbStructureMap["primarykey"] != "yes" {
+			if writableColumn(dbStructureMap) || writableKey(field, dbStructureMap) {
 				// sb.WriteRune('`')
-				sb.WriteString(dbStructureMap["column"])
+				sb.WriteString(column)
 				// sb.WriteRune('`')
 				sb.WriteString(",")
 			}
 		}
 	}
 
-	return table, strings.TrimSuffix(sb.String(), ","), err
+	return tableName(dbStructure, table), strings.TrimSuffix(sb.String(), ","), err
 }
 
 // generateValuesSql creates the part of insert SQL query that adds each entry for each structure
-func generateValuesSql(dbStructure any, t reflect.Type) (string, error) {
+func (db *Database) generateValuesSql(dbStructure any, t reflect.Type) (string, error) {
 	var sb strings.Builder
 	for i := 0; i < t.NumField(); i++ {
 		field := t.Field(i)
 		tag := field.Tag.Get("db")
 		dbStructureMap := decodeTag(tag)
 
-		if reflect.ValueOf(dbStructure).Field(i).CanInterface() {
+		if reflect.ValueOf(dbStructure).Field(i).CanInterface() && isColumn(dbStructureMap) {
 			value := reflect.ValueOf(dbStructure).Field(i).Interface()
 
-			if dbStructureMap["column"] == "" {
+			if db.columnName(field, dbStructureMap) == "" {
 				return "", errors.New("no column name specified for field" + field.Type.Name())
 			}
 
-			if dbStructureMap["omit"] != "yes" && dbStructureMap["primarykey"] != "yes" {
+			if writableColumn(dbStructureMap) || writableKey(field, dbStructureMap) {
+				if dbStructureMap["sensitive"] == "yes" {
+					sb.WriteString(sensitiveMarker)
+				}
+
+				if literal, ok, err := db.valuerLiteral(value); ok {
+					if err != nil {
+						return "", err
+					}
+					sb.WriteString(literal + ",")
+					continue
+				}
+				if b, ok := value.([]byte); ok {
+					sb.WriteString(bytesLiteral(b) + ",")
+					continue
+				}
+
 				switch field.Type.Name() {
 				case "uint", "uint8", "uint16", "uint32", "uint64", "int8", "int16", "int", "int32", "int64":
 					sb.WriteString(fmt.Sprintf("%v,", value))
@@ -68,15 +85,15 @@ func generateValuesSql(dbStructure any, t reflect.Type) (string, error) {
 					sb.WriteString(fmt.Sprintf("%v,", value))
 				case "Time":
 					timeValue := value.(time.Time)
-					// If the model uses readdefault=null and is zero time,
+					// If the model uses readdefault=null (or softdelete=yes) and is zero time,
 					// persist SQL NULL instead of a zero-date timestamp.
-					if dbStructureMap["readdefault"] == "null" && timeValue.IsZero() {
+					if nullWhenZero(dbStructureMap) && timeValue.IsZero() {
 						sb.WriteString("NULL,")
 					} else {
-						sb.WriteString(fmt.Sprintf("'%s',", timeValue.Format("2006-01-02 15:04:05")))
+						sb.WriteString(fmt.Sprintf("'%s',", db.formatTime(timeValue)))
 					}
 				default:
-					l.With("type", field.Type.Name()).With("value", value).Error("type error")
+					l.With("type", field.Type.Name()).With("value", logValue(value, dbStructureMap["sensitive"] == "yes")).Error("type error")
 					sb.WriteString(fmt.Sprintf(`'%s',`, value.(string)))
 				}
 			}
@@ -85,6 +102,35 @@ func generateValuesSql(dbStructure any, t reflect.Type) (string, error) {
 	return fmt.Sprintf("(%s)", strings.TrimSuffix(sb.String(), ",")), nil
 }
 
+// writableColumn reports whether a field is written by the generated INSERT and UPDATE statements.
+// Primary keys, omit=yes and readonly=yes fields, relations and nested structs are never written.
+func writableColumn(dbStructureMap map[string]string) bool {
+	return dbStructureMap["omit"] != "yes" && dbStructureMap["primarykey"] != "yes" && dbStructureMap["readonly"] != "yes" &&
+		isColumn(dbStructureMap)
+}
+
+// readableColumn reports whether a field is expected in, and populated from, query results.
+// omit=yes and writeonly=yes fields, relations and nested structs are never read directly.
+func readableColumn(dbStructureMap map[string]string) bool {
+	return dbStructureMap["omit"] != "yes" && dbStructureMap["writeonly"] != "yes" && isColumn(dbStructureMap)
+}
+
+// isColumn reports whether a field maps to a column. Relations and nested structs (prefix=) hold other structs.
+func isColumn(dbStructureMap map[string]string) bool {
+	return !isRelation(dbStructureMap) && dbStructureMap["prefix"] == ""
+}
+
+// isRelation reports whether a field holds related structs (hasmany or belongsto) rather than a column
+func isRelation(dbStructureMap map[string]string) bool {
+	return dbStructureMap["hasmany"] != "" || dbStructureMap["belongsto"] != ""
+}
+
+// nullWhenZero reports whether a zero time should be written as SQL NULL. Soft delete columns are always
+// NULL until the row is deleted.
+func nullWhenZero(dbStructureMap map[string]string) bool {
+	return dbStructureMap["readdefault"] == "null" || dbStructureMap["softdelete"] == "yes"
+}
+
 // decodeTags Turn a tag string into a map of key/value pairs
 func decodeTag(tag string) map[string]string {
 
@@ -111,8 +157,9 @@ func decodeTag(tag string) map[string]string {
 	// create and fill the map
 	m := make(map[string]string)
 	for _, item := range items {
-		x := strings.Split(item, "=")
-		m[x[0]] = x[1]
+		// Split on the first = only, so values such as patterns can contain one
+		key, value, _ := strings.Cut(item, "=")
+		m[key] = unquote(value)
 	}
 
 	// print the map
@@ -122,17 +169,44 @@ func decodeTag(tag string) map[string]string {
 	return m
 }
 
+// unquote removes a matching pair of quotes around a tag value, e.g. pattern='^[a-z]+$'
+func unquote(value string) string {
+	r := []rune(value)
+	if len(r) >= 2 && r[0] == r[len(r)-1] && unicode.In(r[0], unicode.Quotation_Mark) {
+		return string(r[1 : len(r)-1])
+	}
+	return value
+}
+
 // HexRepresentation Convert a string to a hex representation
 func hexRepresentation(in string) string {
 	return "X'" + fmt.Sprintf("%x", in) + "'"
 	// return "'" + in + "'"
 }
 
-// getStructDetails Get the details of a struct
-func getStructDetails[T any](dbFieldName string) (string, map[string]string, any) {
+// bytesLiteral writes a []byte value as a hex literal, or NULL for a nil slice as database/sql does
+func bytesLiteral(b []byte) string {
+	if b == nil {
+		return "NULL"
+	}
+	return fmt.Sprintf("X'%x'", b)
+}
 
-	var st T
-	t := reflect.TypeOf(st)
+// keyLiteral writes a primary key value for a WHERE clause. UUID keys are encoded for the Database and strings
+// are written in hex, cast to text so they compare as strings (SQLite takes a bare X'..' to be a BLOB).
+// Everything else is written as it is.
+func (db *Database) keyLiteral(value any) (string, error) {
+	if literal, ok, err := db.valuerLiteral(value); ok {
+		return literal, err
+	}
+	if v := reflect.ValueOf(value); v.Kind() == reflect.String {
+		return "CAST(" + hexRepresentation(v.String()) + " AS CHAR)", nil
+	}
+	return fmt.Sprintf("%v", value), nil
+}
+
+// getStructDetails Get the details of a struct
+func (db *Database) getStructDetails(t reflect.Type, dbFieldName string) (string, map[string]string, any) {
 
 	for i := 0; i < t.NumField(); i++ {
 		field := t.Field(i)
@@ -141,7 +215,7 @@ func getStructDetails[T any](dbFieldName string) (string, map[string]string, any
 		// l.INFO("%d. %v (%v), tag: '%v'\n", i+1, field.Name, field.Type.Name(), tag)
 		// l.SPEW(field.Type)
 
-		if dbStructureMap["column"] == dbFieldName {
+		if db.columnName(field, dbStructureMap) == dbFieldName {
 			if field.Type == reflect.TypeOf([]uint8{}) {
 				return field.Name, dbStructureMap, "[]uint8"
 			} else {
@@ -151,3 +225,23 @@ func getStructDetails[T any](dbFieldName string) (string, map[string]string, any
 	}
 	return "", map[string]string{}, ""
 }
+
+// missingColumns returns the columns a struct expects to read that are not in the record
+func (db *Database) missingColumns(t reflect.Type, record Record) []string {
+
+	missing := make([]string, 0)
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		dbStructureMap := decodeTag(field.Tag.Get("db"))
+		column := db.columnName(field, dbStructureMap)
+
+		if column == "" || !field.IsExported() || !readableColumn(dbStructureMap) {
+			continue
+		}
+		if _, ok := record[column]; !ok {
+			missing = append(missing, column)
+		}
+	}
+	return missing
+}
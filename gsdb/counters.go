@@ -1,17 +1,40 @@
 package gsdb
 
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// StartCounter resets a named counter, which then counts every statement run on the Database.
+//
+// Deprecated: the counters are shared by everything using the Database, so concurrent requests count each
+// other's statements. Use WithQueryCounter instead.
 func (db *Database) StartCounter(counterName string) {
 	db.Counters.Lock.Lock()
 	defer db.Counters.Lock.Unlock()
 	db.Counters.Count[counterName] = 0
 }
 
+// GetCounter returns a named counter.
+//
+// Deprecated: use QueryCount.
 func (db *Database) GetCounter(counterName string) int64 {
 	db.Counters.Lock.Lock()
 	defer db.Counters.Lock.Unlock()
 	return db.Counters.Count[counterName]
 }
 
+// incCounters adds one to every named counter, for each statement run
+func (db *Database) incCounters() {
+	db.Counters.Lock.Lock()
+	defer db.Counters.Lock.Unlock()
+
+	for k := range db.Counters.Count {
+		db.Counters.Count[k]++
+	}
+}
+
 func (db *Database) IncCounter(counterName string) {
 	db.Counters.Lock.Lock()
 	defer db.Counters.Lock.Unlock()
@@ -20,3 +43,73 @@ func (db *Database) IncCounter(counterName string) {
 		db.Counters.Count[counterName]++
 	}
 }
+
+// QueryCounts is what a query counter has seen. Queries are reads and Execute statements are writes.
+type QueryCounts struct {
+	Reads        int64
+	Writes       int64
+	Errors       int64
+	RowsReturned int64
+	RowsAffected int64
+	Duration     time.Duration
+}
+
+// Statements is the number of reads and writes
+func (c QueryCounts) Statements() int64 {
+	return c.Reads + c.Writes
+}
+
+type queryCounterKey struct{}
+
+type queryCounter struct {
+	lock   sync.Mutex
+	counts QueryCounts
+	parent *queryCounter
+	shapes map[string]int // statement shapes, for the NPlusOneDetector
+}
+
+// WithQueryCounter returns a context with a new query counter attached. Every statement run with the context (or
+// one derived from it) through the Context functions, such as QueryContext, ExecuteContext and SaveContext, or
+// inside a WithTx using it, is counted. A counter added to a context that already has one also counts towards the
+// outer counter.
+//
+//	ctx := gsdb.WithQueryCounter(r.Context())
+//	next.ServeHTTP(w, r.WithContext(ctx))
+//	counts := gsdb.QueryCount(ctx)
+func WithQueryCounter(ctx context.Context) context.Context {
+	parent, _ := ctx.Value(queryCounterKey{}).(*queryCounter)
+	return context.WithValue(ctx, queryCounterKey{}, &queryCounter{parent: parent})
+}
+
+// QueryCount returns the counts of the counter attached by WithQueryCounter, or zero if there isn't one
+func QueryCount(ctx context.Context) QueryCounts {
+	counter, ok := ctx.Value(queryCounterKey{}).(*queryCounter)
+	if !ok {
+		return QueryCounts{}
+	}
+	counter.lock.Lock()
+	defer counter.lock.Unlock()
+	return counter.counts
+}
+
+// countQuery adds a statement to the counter in the context, and any counters outside it
+func countQuery(ctx context.Context, result HookResult, err error, duration time.Duration) {
+
+	counter, _ := ctx.Value(queryCounterKey{}).(*queryCounter)
+	for ; counter != nil; counter = counter.parent {
+		counter.lock.Lock()
+		switch result.Operation {
+		case OpQuery:
+			counter.counts.Reads++
+		case OpExec:
+			counter.counts.Writes++
+		}
+		if err != nil {
+			counter.counts.Errors++
+		}
+		counter.counts.RowsReturned += result.RowsReturned
+		counter.counts.RowsAffected += result.RowsAffected
+		counter.counts.Duration += duration
+		counter.lock.Unlock()
+	}
+}
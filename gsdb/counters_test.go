@@ -2,7 +2,12 @@ package gsdb
 
 import (
 	"context"
+	"fmt"
+	"log/slog"
+	"sync"
 	"testing"
+
+	"github.com/stretchr/testify/assert"
 )
 
 func TestCounters(t *testing.T) {
@@ -14,3 +19,91 @@ func TestCounters(t *testing.T) {
 		t.Errorf("Expected: %d, got: %d", 1, DB.GetCounter("test"))
 	}
 }
+
+func TestQueryCounter(t *testing.T) {
+	NewSQLite3("test.db", slog.Default(), context.Background())
+	_, _, err := DB.Execute("DROP TABLE IF EXISTS counted;")
+	assert.NoError(t, err)
+	_, _, err = DB.Execute("CREATE TABLE counted (id INTEGER PRIMARY KEY, name TEXT);")
+	assert.NoError(t, err)
+
+	request := WithQueryCounter(context.Background())
+	_, _, err = DB.ExecuteContext(request, "INSERT INTO counted VALUES (1, 'a'), (2, 'b'), (3, 'c');")
+	assert.NoError(t, err)
+
+	// A counter inside the request counts towards both
+	inner := WithQueryCounter(request)
+	records, err := DB.QueryContext(inner, "SELECT * FROM counted;")
+	assert.NoError(t, err)
+	assert.Len(t, records, 3)
+	_, err = DB.QueryContext(inner, "SELECT * FROM missing;")
+	assert.Error(t, err)
+
+	counts := QueryCount(request)
+	assert.Equal(t, int64(2), counts.Reads)
+	assert.Equal(t, int64(1), counts.Writes)
+	assert.Equal(t, int64(1), counts.Errors)
+	assert.Equal(t, int64(3), counts.RowsReturned)
+	assert.Equal(t, int64(3), counts.RowsAffected)
+	assert.Equal(t, int64(3), counts.Statements())
+	assert.Positive(t, counts.Duration)
+
+	assert.Equal(t, int64(2), QueryCount(inner).Reads)
+	assert.Equal(t, int64(0), QueryCount(inner).Writes)
+	assert.Equal(t, QueryCounts{}, QueryCount(context.Background()))
+
+	// Statements without the context aren't counted
+	_, err = DB.Query("SELECT * FROM counted;")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), QueryCount(request).Reads)
+}
+
+func TestQueryCounterConcurrent(t *testing.T) {
+	NewSQLite3("test.db", slog.Default(), context.Background())
+
+	var wg sync.WaitGroup
+	counts := make([]QueryCounts, 8)
+	for i := range counts {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			ctx := WithQueryCounter(context.Background())
+			for j := 0; j <= i; j++ {
+				_, err := QueryStructContext[struct{}](ctx, "SELECT 1;")
+				assert.NoError(t, err)
+			}
+			counts[i] = QueryCount(ctx)
+		}(i)
+	}
+	wg.Wait()
+
+	for i, c := range counts {
+		assert.Equal(t, int64(i+1), c.Reads)
+		assert.Equal(t, int64(i+1), c.RowsReturned)
+	}
+}
+
+func TestCountersConcurrent(t *testing.T) {
+	NewSQLite3("test.db", slog.Default(), context.Background())
+
+	// Starting counters while statements run must not race with them being counted
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(2)
+		go func(i int) {
+			defer wg.Done()
+			DB.StartCounter(fmt.Sprintf("counter %d", i))
+		}(i)
+		go func() {
+			defer wg.Done()
+			_, err := DB.Query("SELECT 1;")
+			assert.NoError(t, err)
+		}()
+	}
+	wg.Wait()
+
+	DB.StartCounter("after")
+	_, _, err := DB.Execute("SELECT 1;")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), DB.GetCounter("after"))
+}
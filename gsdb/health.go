@@ -0,0 +1,153 @@
+package gsdb
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// HealthStatus is the state of the connection to the database
+type HealthStatus string
+
+const (
+	HealthUnknown HealthStatus = "unknown" // not checked yet
+	HealthUp      HealthStatus = "up"
+	HealthDown    HealthStatus = "down"
+	HealthClosed  HealthStatus = "closed"
+)
+
+// Health is the state of the connection as of the last health check
+type Health struct {
+	Status     HealthStatus
+	CheckedAt  time.Time
+	Latency    time.Duration // of the last ping
+	Err        error         // why the last check failed, nil if it didn't
+	Failures   int           // checks failed in a row
+	Reconnects int           // times the connection came back after a failed check
+}
+
+// healthState is kept by the Database for Health. The zero value is ready to use.
+type healthState struct {
+	lock   sync.Mutex
+	health Health
+	stop   chan struct{}
+	done   chan struct{}
+}
+
+func (h *healthState) record(err error, latency time.Duration) {
+
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	h.health.CheckedAt = time.Now()
+	h.health.Latency = latency
+	h.health.Err = err
+	if err != nil {
+		h.health.Status = HealthDown
+		h.health.Failures++
+		return
+	}
+	h.health.Status = HealthUp
+	h.health.Failures = 0
+}
+
+func (h *healthState) reconnected() {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	h.health.Reconnects++
+}
+
+// Health returns the state of the connection as of the last check (see CheckHealth and StartHealthCheck). It
+// never waits for the database, so it's fine to call from a readiness endpoint.
+func (db *Database) Health() Health {
+
+	db.health.lock.Lock()
+	defer db.health.lock.Unlock()
+
+	health := db.health.health
+	if health.Status == "" {
+		health.Status = HealthUnknown
+	}
+	if db.closing.Load() {
+		health.Status = HealthClosed
+	}
+	return health
+}
+
+// CheckHealth pings the database now, connecting first if need be, and returns the new state. The context limits
+// how long it takes. After a failed check the connection is marked as down, so the next operation checks it again
+// (and reconnects) before using it.
+func (db *Database) CheckHealth(ctx context.Context) Health {
+
+	conn, checked, err := db.connectChecked(ctx)
+
+	if errors.Is(err, ErrClosed) {
+		return db.Health()
+	}
+
+	// Connecting pinged the database already, and recorded the result
+	if !checked {
+		start := time.Now()
+		if err == nil {
+			err = conn.PingContext(ctx)
+		}
+		db.health.record(err, time.Since(start))
+	}
+
+	if err != nil {
+		db.Lock.Lock()
+		db.connected = false
+		db.Lock.Unlock()
+		db.Logger.With("error", err.Error()).Warn("Database health check failed")
+	}
+	return db.Health()
+}
+
+// StartHealthCheck checks the health of the connection in the background, straight away and then every interval,
+// until StopHealthCheck or Close is called. Each ping is given the interval to answer.
+//
+//	gsdb.DB.StartHealthCheck(30 * time.Second)
+//	defer gsdb.DB.Close()
+func (db *Database) StartHealthCheck(interval time.Duration) {
+
+	db.StopHealthCheck()
+
+	stop, done := make(chan struct{}), make(chan struct{})
+	db.health.lock.Lock()
+	db.health.stop, db.health.done = stop, done
+	db.health.lock.Unlock()
+
+	go func() {
+		defer close(done)
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			ctx, cancel := context.WithTimeout(db.context(), interval)
+			db.CheckHealth(ctx)
+			cancel()
+
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// StopHealthCheck stops the background health check, and waits for a check in progress to finish
+func (db *Database) StopHealthCheck() {
+
+	db.health.lock.Lock()
+	stop, done := db.health.stop, db.health.done
+	db.health.stop, db.health.done = nil, nil
+	db.health.lock.Unlock()
+
+	if stop != nil {
+		close(stop)
+		<-done
+	}
+}
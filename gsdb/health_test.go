@@ -0,0 +1,193 @@
+package gsdb
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func setupHealthMock(t *testing.T) sqlmock.Sqlmock {
+	New("test/test", slog.Default(), context.Background())
+	db, m, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual), sqlmock.MonitorPingsOption(true))
+	assert.NoError(t, err)
+	DB.dbConnection = db
+	DB.connected = true
+	return m
+}
+
+func TestHealthCheckAndReconnect(t *testing.T) {
+	m := setupHealthMock(t)
+
+	assert.Equal(t, HealthUnknown, DB.Health().Status)
+
+	m.ExpectPing()
+	health := DB.CheckHealth(context.Background())
+	assert.Equal(t, HealthUp, health.Status)
+	assert.False(t, health.CheckedAt.IsZero())
+	assert.NoError(t, health.Err)
+
+	// A failed check marks the connection as down
+	lost := errors.New("connection refused")
+	m.ExpectPing().WillReturnError(lost)
+	health = DB.CheckHealth(context.Background())
+	assert.Equal(t, HealthDown, health.Status)
+	assert.Equal(t, lost, health.Err)
+	assert.Equal(t, 1, health.Failures)
+	assert.False(t, DB.connected)
+
+	// and the next statement checks it again before running
+	m.ExpectPing()
+	m.ExpectExec("UPDATE Users SET status=1").WillReturnResult(sqlmock.NewResult(0, 1))
+	_, _, err := DB.Execute("UPDATE Users SET status=1")
+	assert.NoError(t, err)
+	assert.True(t, DB.connected)
+	assert.Equal(t, 1, DB.Health().Reconnects)
+	assert.Equal(t, HealthUp, DB.Health().Status, "the reconnect is seen without waiting for the next check")
+	assert.Equal(t, 0, DB.Health().Failures)
+
+	m.ExpectPing()
+	health = DB.CheckHealth(context.Background())
+	assert.Equal(t, HealthUp, health.Status)
+	assert.Equal(t, 0, health.Failures)
+	assert.NoError(t, m.ExpectationsWereMet())
+}
+
+func TestCloseDrainsInFlight(t *testing.T) {
+	m := setupHealthMock(t)
+
+	m.ExpectBegin()
+	m.ExpectExec("UPDATE Users SET status=2").WillReturnResult(sqlmock.NewResult(0, 1))
+	m.ExpectCommit()
+	m.ExpectClose()
+
+	started, finish := make(chan struct{}), make(chan struct{})
+	txErr := make(chan error)
+	go func() {
+		txErr <- DB.WithTx(context.Background(), func(tx *Tx) error {
+			close(started)
+			<-finish
+			_, _, err := tx.Execute("UPDATE Users SET status=2")
+			return err
+		})
+	}()
+	<-started
+
+	closed := make(chan error)
+	go func() {
+		closed <- DB.Close()
+	}()
+
+	// New operations are turned away while the transaction finishes
+	assert.Eventually(t, func() bool {
+		return DB.Health().Status == HealthClosed
+	}, time.Second, time.Millisecond)
+	_, _, err := DB.Execute("UPDATE Users SET status=3")
+	assert.ErrorIs(t, err, ErrClosed)
+
+	select {
+	case <-closed:
+		t.Fatal("Close returned before the transaction finished")
+	default:
+	}
+
+	close(finish)
+	assert.NoError(t, <-txErr)
+	assert.NoError(t, <-closed)
+	assert.Equal(t, HealthClosed, DB.Health().Status)
+	assert.NoError(t, DB.Close())
+	assert.NoError(t, m.ExpectationsWereMet())
+}
+
+func TestCloseContextTimeout(t *testing.T) {
+	m := setupHealthMock(t)
+	m.ExpectClose()
+
+	_, release, err := DB.acquire(context.Background())
+	assert.NoError(t, err)
+	defer release()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, DB.CloseContext(ctx), context.DeadlineExceeded)
+	assert.NoError(t, m.ExpectationsWereMet())
+}
+
+func TestConnectEmptyDSN(t *testing.T) {
+	New("", slog.Default(), context.Background())
+
+	// The lock used to be kept after this error, so the second call would hang
+	for i := 0; i < 2; i++ {
+		_, _, err := DB.Execute("SELECT 1")
+		assert.EqualError(t, err, "empty database dsn")
+	}
+}
+
+func TestStartHealthCheck(t *testing.T) {
+	NewSQLite3("test.db", slog.Default(), context.Background())
+
+	DB.StartHealthCheck(5 * time.Millisecond)
+	assert.Eventually(t, func() bool {
+		return DB.Health().Status == HealthUp
+	}, time.Second, time.Millisecond)
+
+	assert.NoError(t, DB.Close())
+	assert.Equal(t, HealthClosed, DB.Health().Status)
+
+	_, err := DB.Query("SELECT 1")
+	assert.ErrorIs(t, err, ErrClosed)
+}
+
+func TestConnectOpenFailure(t *testing.T) {
+	db := &Database{DSN: "test", driverName: "unregistered", Logger: slog.Default()}
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	// The lock used to be kept when sql.Open failed, so the second call would hang
+	for i := 0; i < 2; i++ {
+		_, _, err := db.ExecuteContext(ctx, "SELECT 1")
+		assert.ErrorContains(t, err, "unknown driver")
+		if assert.True(t, db.Lock.TryLock(), "the lock is released") {
+			db.Lock.Unlock()
+		}
+	}
+	assert.NoError(t, db.Close())
+}
+
+func TestHealthWhileReconnecting(t *testing.T) {
+	m := setupHealthMock(t)
+	DB.connected = false
+
+	// Reconnecting pings the database, which is the check, so it isn't pinged twice
+	m.ExpectPing().WillDelayFor(200 * time.Millisecond)
+	checked := make(chan Health)
+	go func() {
+		checked <- DB.CheckHealth(context.Background())
+	}()
+
+	// Pinging doesn't hold the lock, so Health (and Close, and other statements) aren't held up
+	time.Sleep(20 * time.Millisecond)
+	start := time.Now()
+	assert.Equal(t, HealthUnknown, DB.Health().Status)
+	if assert.True(t, DB.Lock.TryLock()) {
+		DB.Lock.Unlock()
+	}
+	assert.Less(t, time.Since(start), 50*time.Millisecond)
+
+	assert.Equal(t, HealthUp, (<-checked).Status)
+	assert.NoError(t, m.ExpectationsWereMet())
+
+	// CheckHealth gives up when its context is done
+	DB.connected = false
+	m.ExpectPing().WillDelayFor(time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	start = time.Now()
+	health := DB.CheckHealth(ctx)
+	assert.Equal(t, HealthDown, health.Status)
+	assert.Less(t, time.Since(start), 500*time.Millisecond)
+}
@@ -0,0 +1,90 @@
+package gsdb
+
+import "reflect"
+
+// Lifecycle hooks. If an entity implements any of these, gsdb calls them at the matching point. An error from a
+// Before hook aborts the operation and is returned to the caller. Hooks that change the entity should use a pointer
+// receiver, the changes are then written by the generated SQL (and kept, if a pointer was passed in).
+
+// BeforeSaver is called by Save (and Tracked.SaveChanges) before the INSERT or UPDATE is generated
+type BeforeSaver interface {
+	BeforeSave() error
+}
+
+// AfterSaver is called by Save (and Tracked.SaveChanges) once the statement has run successfully
+type AfterSaver interface {
+	AfterSave()
+}
+
+// BeforeInserter is called by Insert, InsertMany and Save before the INSERT is generated
+type BeforeInserter interface {
+	BeforeInsert() error
+}
+
+// BeforeUpdater is called by Update and Save before the UPDATE is generated
+type BeforeUpdater interface {
+	BeforeUpdate() error
+}
+
+// BeforeDeleter is called by Delete before the DELETE (or soft delete UPDATE) is generated
+type BeforeDeleter interface {
+	BeforeDelete() error
+}
+
+// AfterLoader is called by QueryStruct for each struct it has loaded
+type AfterLoader interface {
+	AfterLoad() error
+}
+
+// structPointer returns a pointer to the struct, so pointer receiver hooks can be called. A pointer is returned
+// as it is, otherwise the pointer is to a copy, so a struct passed by value is never changed.
+func structPointer(dbStructure any) any {
+	v := reflect.ValueOf(dbStructure)
+	if v.Kind() != reflect.Struct {
+		return dbStructure
+	}
+	p := reflect.New(v.Type())
+	p.Elem().Set(v)
+	return p.Interface()
+}
+
+func beforeSave(entity any) error {
+	if h, ok := entity.(BeforeSaver); ok {
+		return h.BeforeSave()
+	}
+	return nil
+}
+
+func afterSave(entity any) {
+	if h, ok := entity.(AfterSaver); ok {
+		h.AfterSave()
+	}
+}
+
+func beforeInsert(entity any) error {
+	if h, ok := entity.(BeforeInserter); ok {
+		return h.BeforeInsert()
+	}
+	return nil
+}
+
+func beforeUpdate(entity any) error {
+	if h, ok := entity.(BeforeUpdater); ok {
+		return h.BeforeUpdate()
+	}
+	return nil
+}
+
+func beforeDelete(entity any) error {
+	if h, ok := entity.(BeforeDeleter); ok {
+		return h.BeforeDelete()
+	}
+	return nil
+}
+
+func afterLoad(entity any) error {
+	if h, ok := entity.(AfterLoader); ok {
+		return h.AfterLoad()
+	}
+	return nil
+}
@@ -0,0 +1,125 @@
+package gsdb
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+type HookPerson struct {
+	Id     int    `db:"column=id primarykey=yes table=Users"`
+	Name   string `db:"column=name"`
+	Status int    `db:"column=status"`
+	calls  []string
+	fail   string
+}
+
+func (p *HookPerson) hook(name string) error {
+	p.calls = append(p.calls, name)
+	if p.fail == name {
+		return errors.New(name + " failed")
+	}
+	return nil
+}
+
+func (p *HookPerson) BeforeSave() error {
+	p.Name = strings.TrimSpace(p.Name)
+	return p.hook("BeforeSave")
+}
+
+func (p *HookPerson) AfterSave() {
+	_ = p.hook("AfterSave")
+}
+
+func (p *HookPerson) BeforeInsert() error {
+	p.Status = 1
+	return p.hook("BeforeInsert")
+}
+
+func (p *HookPerson) BeforeUpdate() error {
+	return p.hook("BeforeUpdate")
+}
+
+func (p *HookPerson) BeforeDelete() error {
+	return p.hook("BeforeDelete")
+}
+
+func (p *HookPerson) AfterLoad() error {
+	p.Name = strings.ToUpper(p.Name)
+	if p.Name == "FAIL" {
+		return errors.New("AfterLoad failed")
+	}
+	return nil
+}
+
+func TestHooksSave(t *testing.T) {
+	mock, expectedExec := setupSaveTestMock(t, `INSERT INTO Users(name,status) VALUES (X'54657374',1);`)
+	expectedExec.WillReturnResult(sqlmock.NewResult(1, 1))
+
+	entry := HookPerson{Name: "  Test  "}
+	_, _, err := DB.Save(&entry, entry.Id)
+	assert.NoError(t, err)
+	assert.NoError(t, (*mock).ExpectationsWereMet())
+	assert.Equal(t, []string{"BeforeSave", "BeforeInsert", "AfterSave"}, entry.calls)
+	assert.Equal(t, "Test", entry.Name)
+
+	// A struct passed by value is not changed, but the hooks still shape the SQL
+	mock, expectedExec = setupSaveTestMock(t, `UPDATE Users SET name=X'54657374',status=0 WHERE id=2;`)
+	expectedExec.WillReturnResult(sqlmock.NewResult(0, 1))
+	byValue := HookPerson{Id: 2, Name: " Test "}
+	_, _, err = DB.Save(byValue, byValue.Id)
+	assert.NoError(t, err)
+	assert.NoError(t, (*mock).ExpectationsWereMet())
+	assert.Nil(t, byValue.calls)
+}
+
+func TestHooksAbort(t *testing.T) {
+	mock, _ := setupSaveTestMock(t, `UPDATE Users SET name=X'54657374',status=0 WHERE id=2;`)
+
+	for _, hook := range []string{"BeforeSave", "BeforeUpdate"} {
+		entry := HookPerson{Id: 2, Name: "Test", fail: hook}
+		_, _, err := DB.Save(&entry, entry.Id)
+		assert.EqualError(t, err, hook+" failed")
+		assert.NotContains(t, entry.calls, "AfterSave")
+	}
+	assert.Error(t, (*mock).ExpectationsWereMet(), "nothing should have been executed")
+
+	_, err := DB.Insert(&HookPerson{Name: "Test", fail: "BeforeInsert"})
+	assert.EqualError(t, err, "BeforeInsert failed")
+
+	_, err = InsertMany([]HookPerson{{Name: "Test", fail: "BeforeInsert"}})
+	assert.EqualError(t, err, "BeforeInsert failed")
+
+	entry := HookPerson{Id: 2, fail: "BeforeDelete"}
+	_, err = DB.Delete(&entry)
+	assert.EqualError(t, err, "BeforeDelete failed")
+
+	entry.fail = ""
+	sql, err := DB.Delete(&entry)
+	assert.NoError(t, err)
+	assert.Equal(t, "DELETE FROM Users WHERE id=2;", sql)
+	assert.Equal(t, []string{"BeforeDelete", "BeforeDelete"}, entry.calls)
+}
+
+func TestHooksAfterLoad(t *testing.T) {
+	NewSQLite3("test.db", slog.Default(), context.Background())
+
+	_, err := DB.dbConnection.Exec("DROP TABLE IF EXISTS Users;")
+	assert.NoError(t, err)
+	_, err = DB.dbConnection.Exec("CREATE TABLE Users (id INTEGER PRIMARY KEY AUTOINCREMENT, name TEXT, status INTEGER);")
+	assert.NoError(t, err)
+	_, err = DB.dbConnection.Exec("INSERT INTO Users (name, status) VALUES ('test', 1), ('fail', 1);")
+	assert.NoError(t, err)
+
+	p, err := QuerySingleStruct[HookPerson]("SELECT * FROM Users WHERE id = 1")
+	assert.NoError(t, err)
+	assert.Equal(t, "TEST", p.Name)
+
+	_, err = QueryStruct[HookPerson]("SELECT * FROM Users")
+	assert.EqualError(t, err, "AfterLoad failed")
+}
@@ -0,0 +1,198 @@
+package gsdb
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// metricBuckets are the upper bounds, in seconds, of the query latency histogram
+var metricBuckets = []float64{0.001, 0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+var (
+	sqlVerb  = regexp.MustCompile(`^\s*(\w+)`)
+	sqlTable = regexp.MustCompile("(?i)\\b(?:FROM|INTO|UPDATE|JOIN)\\s+[`\"]?([\\w.]+)")
+)
+
+type metricKey struct {
+	operation string
+	table     string
+}
+
+type histogram struct {
+	buckets []int64
+	sum     float64
+	count   int64
+}
+
+// metrics is what the Database has seen, for the MetricsHandler. The zero value is ready to use.
+type metrics struct {
+	lock        sync.Mutex
+	queries     map[metricKey]int64
+	errors      map[metricKey]int64
+	latency     map[string]*histogram
+	rowsRead    int64
+	rowsWritten int64
+}
+
+// statementLabels returns the operation (the first word of the statement, e.g. select) and the first table of a
+// statement, for labelling metrics
+func statementLabels(sql string) (operation string, table string) {
+	operation = "other"
+	if m := sqlVerb.FindStringSubmatch(sql); m != nil {
+		operation = strings.ToLower(m[1])
+	}
+	if m := sqlTable.FindStringSubmatch(sql); m != nil {
+		table = m[1]
+	}
+	return operation, table
+}
+
+// observe records a statement that has run
+func (m *metrics) observe(sql string, result HookResult, err error, duration time.Duration) {
+
+	operation, table := statementLabels(sql)
+	key := metricKey{operation: operation, table: table}
+
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	if m.queries == nil {
+		m.queries = make(map[metricKey]int64)
+		m.errors = make(map[metricKey]int64)
+		m.latency = make(map[string]*histogram)
+	}
+
+	m.queries[key]++
+	if err != nil {
+		m.errors[key]++
+	}
+
+	h, ok := m.latency[operation]
+	if !ok {
+		h = &histogram{buckets: make([]int64, len(metricBuckets))}
+		m.latency[operation] = h
+	}
+	seconds := duration.Seconds()
+	for i, bound := range metricBuckets {
+		if seconds <= bound {
+			h.buckets[i]++
+		}
+	}
+	h.sum += seconds
+	h.count++
+
+	m.rowsRead += result.RowsReturned
+	m.rowsWritten += result.RowsAffected
+}
+
+// MetricsHandler serves the metrics of the Database in the Prometheus text exposition format: statements and
+// errors by operation and table, a latency histogram by operation, rows read and written, and the connection pool
+// stats.
+//
+//	http.Handle("/metrics", gsdb.DB.MetricsHandler())
+func (db *Database) MetricsHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		db.WriteMetrics(w)
+	})
+}
+
+// WriteMetrics writes the metrics of the Database in the Prometheus text exposition format
+func (db *Database) WriteMetrics(w io.Writer) {
+
+	m := &db.metrics
+	m.lock.Lock()
+
+	writeHeader(w, "gsdb_queries_total", "counter", "Statements run, by operation and table.")
+	for _, key := range sortedKeys(m.queries) {
+		fmt.Fprintf(w, "gsdb_queries_total{operation=%s,table=%s} %d\n", labelValue(key.operation), labelValue(key.table), m.queries[key])
+	}
+
+	writeHeader(w, "gsdb_query_errors_total", "counter", "Statements that returned an error, by operation and table.")
+	for _, key := range sortedKeys(m.errors) {
+		fmt.Fprintf(w, "gsdb_query_errors_total{operation=%s,table=%s} %d\n", labelValue(key.operation), labelValue(key.table), m.errors[key])
+	}
+
+	writeHeader(w, "gsdb_query_duration_seconds", "histogram", "Statement latency, by operation.")
+	operations := make([]string, 0, len(m.latency))
+	for operation := range m.latency {
+		operations = append(operations, operation)
+	}
+	sort.Strings(operations)
+	for _, operation := range operations {
+		h := m.latency[operation]
+		for i, bound := range metricBuckets {
+			fmt.Fprintf(w, "gsdb_query_duration_seconds_bucket{operation=%s,le=\"%s\"} %d\n", labelValue(operation), formatFloat(bound), h.buckets[i])
+		}
+		fmt.Fprintf(w, "gsdb_query_duration_seconds_bucket{operation=%s,le=\"+Inf\"} %d\n", labelValue(operation), h.count)
+		fmt.Fprintf(w, "gsdb_query_duration_seconds_sum{operation=%s} %s\n", labelValue(operation), formatFloat(h.sum))
+		fmt.Fprintf(w, "gsdb_query_duration_seconds_count{operation=%s} %d\n", labelValue(operation), h.count)
+	}
+
+	writeHeader(w, "gsdb_rows_read_total", "counter", "Rows returned by queries.")
+	fmt.Fprintf(w, "gsdb_rows_read_total %d\n", m.rowsRead)
+	writeHeader(w, "gsdb_rows_written_total", "counter", "Rows affected by Execute.")
+	fmt.Fprintf(w, "gsdb_rows_written_total %d\n", m.rowsWritten)
+
+	m.lock.Unlock()
+
+	db.Lock.Lock()
+	conn := db.dbConnection
+	db.Lock.Unlock()
+
+	if conn == nil {
+		return
+	}
+	stats := conn.Stats()
+	for _, gauge := range []struct {
+		name, kind, help string
+		value            string
+	}{
+		{"gsdb_pool_max_open_connections", "gauge", "Maximum number of open connections.", strconv.Itoa(stats.MaxOpenConnections)},
+		{"gsdb_pool_open_connections", "gauge", "Open connections, in use and idle.", strconv.Itoa(stats.OpenConnections)},
+		{"gsdb_pool_in_use_connections", "gauge", "Connections in use.", strconv.Itoa(stats.InUse)},
+		{"gsdb_pool_idle_connections", "gauge", "Idle connections.", strconv.Itoa(stats.Idle)},
+		{"gsdb_pool_wait_count_total", "counter", "Times a connection had to be waited for.", strconv.FormatInt(stats.WaitCount, 10)},
+		{"gsdb_pool_wait_duration_seconds_total", "counter", "Time spent waiting for connections.", formatFloat(stats.WaitDuration.Seconds())},
+		{"gsdb_pool_max_idle_closed_total", "counter", "Connections closed because of the idle limit.", strconv.FormatInt(stats.MaxIdleClosed, 10)},
+		{"gsdb_pool_max_idle_time_closed_total", "counter", "Connections closed because of the idle timeout.", strconv.FormatInt(stats.MaxIdleTimeClosed, 10)},
+		{"gsdb_pool_max_lifetime_closed_total", "counter", "Connections closed because of the lifetime limit.", strconv.FormatInt(stats.MaxLifetimeClosed, 10)},
+	} {
+		writeHeader(w, gauge.name, gauge.kind, gauge.help)
+		fmt.Fprintf(w, "%s %s\n", gauge.name, gauge.value)
+	}
+}
+
+func writeHeader(w io.Writer, name, kind, help string) {
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, kind)
+}
+
+func sortedKeys(m map[metricKey]int64) []metricKey {
+	keys := make([]metricKey, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].operation != keys[j].operation {
+			return keys[i].operation < keys[j].operation
+		}
+		return keys[i].table < keys[j].table
+	})
+	return keys
+}
+
+// labelValue quotes a label value, escaping \, " and newlines
+func labelValue(value string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(value) + `"`
+}
+
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
@@ -0,0 +1,91 @@
+package gsdb
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStatementLabels(t *testing.T) {
+	tests := map[string][2]string{
+		"SELECT * FROM orders WHERE id=1":              {"select", "orders"},
+		"  insert INTO order_lines(item) VALUES ('a')": {"insert", "order_lines"},
+		"UPDATE `customers` SET name='x'":              {"update", "customers"},
+		"DELETE FROM shop.orders":                      {"delete", "shop.orders"},
+		"SELECT 1":                                     {"select", ""},
+		"":                                             {"other", ""},
+		"EXPLAIN SELECT o.id FROM orders o JOIN customers c": {"explain", "orders"},
+	}
+	for sql, expected := range tests {
+		operation, table := statementLabels(sql)
+		assert.Equal(t, expected, [2]string{operation, table}, sql)
+	}
+}
+
+func TestMetricsHandler(t *testing.T) {
+	setupPreloadTables(t)
+
+	_, err := DB.Query("SELECT * FROM orders")
+	assert.NoError(t, err)
+	_, err = DB.Query("SELECT * FROM orders WHERE id=?", 10)
+	assert.NoError(t, err)
+	_, _, err = DB.Execute("UPDATE customers SET name='Carol' WHERE id=1")
+	assert.NoError(t, err)
+	_, err = DB.Query("SELECT * FROM missing")
+	assert.Error(t, err)
+
+	recorder := httptest.NewRecorder()
+	DB.MetricsHandler().ServeHTTP(recorder, httptest.NewRequest("GET", "/metrics", nil))
+	body, _ := io.ReadAll(recorder.Result().Body)
+	out := string(body)
+
+	assert.Equal(t, "text/plain; version=0.0.4; charset=utf-8", recorder.Header().Get("Content-Type"))
+	assert.Contains(t, out, "# TYPE gsdb_queries_total counter\n")
+	assert.Contains(t, out, `gsdb_queries_total{operation="select",table="orders"} 2`+"\n")
+	assert.Contains(t, out, `gsdb_queries_total{operation="update",table="customers"} 1`+"\n")
+	assert.Contains(t, out, `gsdb_query_errors_total{operation="select",table="missing"} 1`+"\n")
+	assert.NotContains(t, out, `gsdb_query_errors_total{operation="select",table="orders"}`)
+	assert.Contains(t, out, "# TYPE gsdb_query_duration_seconds histogram\n")
+	assert.Contains(t, out, `gsdb_query_duration_seconds_bucket{operation="select",le="+Inf"} 3`+"\n")
+	assert.Contains(t, out, `gsdb_query_duration_seconds_count{operation="update"} 1`+"\n")
+	assert.Contains(t, out, "gsdb_rows_read_total 4\n")
+	assert.Contains(t, out, "gsdb_rows_written_total 1\n")
+	assert.Contains(t, out, "# TYPE gsdb_pool_open_connections gauge\n")
+	assert.Contains(t, out, "gsdb_pool_max_open_connections 0\n")
+}
+
+func TestMetricsNotConnected(t *testing.T) {
+	New("", slog.Default(), context.Background())
+
+	recorder := httptest.NewRecorder()
+	DB.MetricsHandler().ServeHTTP(recorder, httptest.NewRequest("GET", "/metrics", nil))
+	out := recorder.Body.String()
+	assert.Contains(t, out, "gsdb_rows_read_total 0\n")
+	assert.NotContains(t, out, "gsdb_pool_")
+}
+
+func TestMetricsWhileConnecting(t *testing.T) {
+	// A scrape running alongside the first statement, which opens the pool (run with -race)
+	db := &Database{DSN: "file::memory:", driverName: "sqlite3", Logger: slog.Default()}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 100; i++ {
+			db.WriteMetrics(io.Discard)
+		}
+	}()
+
+	_, _, err := db.Execute("SELECT 1")
+	assert.NoError(t, err)
+	<-done
+	assert.NoError(t, db.Close())
+}
+
+func TestLabelValue(t *testing.T) {
+	assert.Equal(t, `"a\\b\"c\nd"`, labelValue("a\\b\"c\nd"))
+}
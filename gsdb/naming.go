@@ -0,0 +1,89 @@
+package gsdb
+
+import (
+	"reflect"
+	"strings"
+	"unicode"
+)
+
+// NamingStrategy turns a struct field name into a column name. It's only used for exported fields that don't
+// have an explicit column= tag, the tag always wins. When Database.NamingStrategy is nil (the default) every
+// field needs a column= tag.
+type NamingStrategy func(fieldName string) string
+
+// TableNamer can be implemented by a struct to provide its table name instead of using the table= tag.
+// If both are present, the tag wins.
+type TableNamer interface {
+	TableName() string
+}
+
+// SnakeCase maps CustomerID to customer_id and CreatedAt to created_at
+func SnakeCase(fieldName string) string {
+	var sb strings.Builder
+	runes := []rune(fieldName)
+
+	for i, r := range runes {
+		if unicode.IsUpper(r) {
+			// Start a new word when going from lower to upper (createdAt) or at the end of an
+			// acronym (IDNumber -> id_number)
+			if i > 0 && (unicode.IsLower(runes[i-1]) || unicode.IsDigit(runes[i-1]) ||
+				(i+1 < len(runes) && unicode.IsLower(runes[i+1]) && unicode.IsUpper(runes[i-1]))) {
+				sb.WriteRune('_')
+			}
+			sb.WriteRune(unicode.ToLower(r))
+			continue
+		}
+		sb.WriteRune(r)
+	}
+	return sb.String()
+}
+
+// LowerCamelCase maps CustomerID to customerID and CreatedAt to createdAt
+func LowerCamelCase(fieldName string) string {
+	runes := []rune(fieldName)
+
+	// Lower the leading acronym as a whole, so ID becomes id and URLPath becomes urlPath
+	for i := 0; i < len(runes) && unicode.IsUpper(runes[i]); i++ {
+		if i > 0 && i+1 < len(runes) && unicode.IsLower(runes[i+1]) {
+			break
+		}
+		runes[i] = unicode.ToLower(runes[i])
+	}
+	return string(runes)
+}
+
+// ExactCase uses the field name as it is
+func ExactCase(fieldName string) string {
+	return fieldName
+}
+
+// columnName returns the column a struct field is mapped to. The column= tag takes priority, otherwise the
+// Database NamingStrategy is used. An empty string means the field isn't mapped, which is always the case for relations and nested structs.
+func (db *Database) columnName(field reflect.StructField, dbStructureMap map[string]string) string {
+	if dbStructureMap["column"] != "" {
+		return dbStructureMap["column"]
+	}
+	if db == nil || !isColumn(dbStructureMap) || db.NamingStrategy == nil || !field.IsExported() {
+		return ""
+	}
+	return db.NamingStrategy(field.Name)
+}
+
+// tableName returns the table= tag if set, else the TableName() of the structure if it implements TableNamer,
+// either on the struct or (as is usual) on a pointer to it
+func tableName(dbStructure any, tagTable string) string {
+	if tagTable != "" {
+		return tagTable
+	}
+	if tn, ok := dbStructure.(TableNamer); ok {
+		return tn.TableName()
+	}
+	if v := reflect.ValueOf(dbStructure); v.IsValid() && v.Kind() != reflect.Pointer {
+		p := reflect.New(v.Type())
+		p.Elem().Set(v)
+		if tn, ok := p.Interface().(TableNamer); ok {
+			return tn.TableName()
+		}
+	}
+	return ""
+}
@@ -0,0 +1,145 @@
+package gsdb
+
+import (
+	"context"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type NamingPerson struct {
+	Id         int `db:"column=id primarykey=yes"`
+	FirstName  string
+	CustomerID int
+	Dtadded    time.Time `db:"column=date_added"`
+	Ignored    int       `db:"omit=yes"`
+	internal   int
+}
+
+func (NamingPerson) TableName() string {
+	return "people"
+}
+
+type PointerNamedPerson struct {
+	Id        int `db:"column=id primarykey=yes"`
+	FirstName string
+}
+
+func (*PointerNamedPerson) TableName() string {
+	return "pointer_people"
+}
+
+func TestNamingStrategies(t *testing.T) {
+	tests := []struct {
+		in         string
+		snake      string
+		lowerCamel string
+	}{
+		{"Name", "name", "name"},
+		{"FirstName", "first_name", "firstName"},
+		{"CustomerID", "customer_id", "customerID"},
+		{"ID", "id", "id"},
+		{"URLPath", "url_path", "urlPath"},
+		{"Address2Line", "address2_line", "address2Line"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.in, func(t *testing.T) {
+			assert.Equal(t, tc.snake, SnakeCase(tc.in))
+			assert.Equal(t, tc.lowerCamel, LowerCamelCase(tc.in))
+			assert.Equal(t, tc.in, ExactCase(tc.in))
+		})
+	}
+}
+
+func TestNamingStrategyInsertUpdate(t *testing.T) {
+	New("test/test", slog.Default(), context.Background())
+	DB.NamingStrategy = SnakeCase
+	defer func() { DB.NamingStrategy = nil }()
+
+	entry := NamingPerson{
+		Id:         7,
+		FirstName:  "Test",
+		CustomerID: 3,
+		Dtadded:    time.Date(2025, time.December, 25, 15, 29, 25, 0, time.UTC),
+		internal:   1,
+	}
+
+	sqlQuery, err := DB.Insert(entry)
+	assert.NoError(t, err)
+	assert.Equal(t, "INSERT INTO people(first_name,customer_id,date_added) VALUES (X'54657374',3,'2025-12-25 15:29:25');", sqlQuery)
+
+	sqlQuery, err = DB.Update(entry)
+	assert.NoError(t, err)
+	assert.Equal(t, "UPDATE people SET first_name=X'54657374',customer_id=3,date_added='2025-12-25 15:29:25' WHERE id=7;", sqlQuery)
+
+	// The table= tag takes priority over TableName()
+	type Tagged struct {
+		Id        int `db:"column=id primarykey=yes table=tagged"`
+		FirstName string
+	}
+	sqlQuery, err = DB.Insert(Tagged{FirstName: "Test"})
+	assert.NoError(t, err)
+	assert.Equal(t, "INSERT INTO tagged(first_name) VALUES (X'54657374');", sqlQuery)
+}
+
+func TestNamingStrategyNotSet(t *testing.T) {
+	New("test/test", slog.Default(), context.Background())
+
+	_, err := DB.Insert(NamingPerson{FirstName: "Test"})
+	assert.EqualError(t, err, "no column name specified for field FirstName")
+}
+
+func TestNamingStrategyQueryStruct(t *testing.T) {
+	NewSQLite3("test.db", slog.Default(), context.Background())
+	DB.NamingStrategy = SnakeCase
+	defer func() { DB.NamingStrategy = nil }()
+
+	_, err := DB.dbConnection.Exec("DROP TABLE IF EXISTS people;")
+	assert.NoError(t, err)
+	_, err = DB.dbConnection.Exec("CREATE TABLE people (id INTEGER PRIMARY KEY AUTOINCREMENT, first_name TEXT, customer_id INTEGER, date_added DATETIME);")
+	assert.NoError(t, err)
+
+	sqlQuery, err := DB.Insert(NamingPerson{FirstName: "Test", CustomerID: 42, Dtadded: time.Now().UTC()})
+	assert.NoError(t, err)
+	_, _, err = DB.Execute(sqlQuery)
+	assert.NoError(t, err)
+
+	result, err := QuerySingleStruct[NamingPerson]("SELECT * FROM people")
+	assert.NoError(t, err)
+	assert.Equal(t, 1, result.Id)
+	assert.Equal(t, "Test", result.FirstName)
+	assert.Equal(t, 42, result.CustomerID)
+}
+
+func TestTableNamePointerReceiver(t *testing.T) {
+	New("test/test", slog.Default(), context.Background())
+	DB.NamingStrategy = SnakeCase
+
+	sqlQuery, err := DB.Insert(PointerNamedPerson{FirstName: "Test"})
+	assert.NoError(t, err)
+	assert.Equal(t, "INSERT INTO pointer_people(first_name) VALUES (X'54657374');", sqlQuery)
+
+	sqlQuery, err = DB.Update(PointerNamedPerson{Id: 2, FirstName: "Test"})
+	assert.NoError(t, err)
+	assert.Equal(t, "UPDATE pointer_people SET first_name=X'54657374' WHERE id=2;", sqlQuery)
+
+	sqlQuery, err = DB.Delete(&PointerNamedPerson{Id: 2})
+	assert.NoError(t, err)
+	assert.Equal(t, "DELETE FROM pointer_people WHERE id=2;", sqlQuery)
+}
+
+func TestNamingStrategyOfReceiver(t *testing.T) {
+	New("test/test", slog.Default(), context.Background())
+
+	// The strategy of the Database the statement is built by is used, not the one of DB
+	other := &Database{NamingStrategy: LowerCamelCase}
+	sqlQuery, err := other.Insert(NamingPerson{FirstName: "Test", CustomerID: 3})
+	assert.NoError(t, err)
+	assert.Equal(t, "INSERT INTO people(firstName,customerID,date_added) VALUES (X'54657374',3,'0001-01-01 00:00:00');", sqlQuery)
+
+	_, err = DB.Insert(NamingPerson{FirstName: "Test"})
+	assert.EqualError(t, err, "no column name specified for field FirstName")
+}
@@ -0,0 +1,90 @@
+package gsdb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"regexp"
+	"runtime/debug"
+	"strings"
+)
+
+// ErrNPlusOne is returned in strict mode when the same statement shape runs too many times in one scope
+var ErrNPlusOne = errors.New("N+1 query detected")
+
+// NPlusOneDetector looks for the same statement being run over and over in a loop, e.g. a QuerySingleStruct for
+// each row of another query. It's meant for development and tests. The scope is a context with a query counter
+// (see WithQueryCounter), typically one per request or test, and statements run without one aren't checked.
+//
+// Statements are compared by shape, with literals, placeholders and IN lists stripped out, so
+// SELECT * FROM lines WHERE order_id=1 and ... order_id=2 are the same. When a shape runs more than Threshold
+// times in a scope, a warning with a stack trace is logged through Database.Logger, once per shape. In Strict
+// mode the statement isn't run, and ErrNPlusOne is returned instead, so the test fails.
+//
+//	gsdb.DB.NPlusOne = &gsdb.NPlusOneDetector{Threshold: 5, Strict: true}
+type NPlusOneDetector struct {
+	Threshold int
+	Strict    bool
+}
+
+var (
+	sqlHexLiteral    = regexp.MustCompile(`(?i)\bx'[0-9a-f]*'`)
+	sqlStringLiteral = regexp.MustCompile(`'(?:[^']|'')*'`)
+	sqlNumberedParam = regexp.MustCompile(`[$:]\d+`)
+	sqlNumber        = regexp.MustCompile(`\b\d+(?:\.\d+)?\b`)
+	sqlParamList     = regexp.MustCompile(`\?(?:\s*,\s*\?)+`)
+	sqlWhitespace    = regexp.MustCompile(`\s+`)
+)
+
+// NormalizeSQL returns the shape of a statement, with every literal and placeholder replaced by ?, lists of them
+// collapsed to a single ?, and the whitespace tidied up
+func NormalizeSQL(sql string) string {
+	shape := sqlHexLiteral.ReplaceAllString(sql, "?")
+	shape = sqlStringLiteral.ReplaceAllString(shape, "?")
+	shape = sqlNumberedParam.ReplaceAllString(shape, "?")
+	shape = sqlNumber.ReplaceAllString(shape, "?")
+	shape = sqlParamList.ReplaceAllString(shape, "?")
+	shape = sqlWhitespace.ReplaceAllString(shape, " ")
+	return strings.TrimSuffix(strings.TrimSpace(shape), ";")
+}
+
+// detectNPlusOne counts the shape of the statement in the query counter of the context, and reports it once the
+// threshold is passed
+func (db *Database) detectNPlusOne(ctx context.Context, operation Operation, sql string) error {
+
+	if db.NPlusOne == nil || (operation != OpExec && operation != OpQuery) {
+		return nil
+	}
+	counter, ok := ctx.Value(queryCounterKey{}).(*queryCounter)
+	if !ok {
+		return nil
+	}
+
+	shape := NormalizeSQL(sql)
+	counter.lock.Lock()
+	if counter.shapes == nil {
+		counter.shapes = make(map[string]int)
+	}
+	counter.shapes[shape]++
+	count := counter.shapes[shape]
+	counter.lock.Unlock()
+
+	if count <= db.NPlusOne.Threshold {
+		return nil
+	}
+
+	if count == db.NPlusOne.Threshold+1 {
+		logger := db.Logger
+		if logger == nil {
+			logger = slog.Default()
+		}
+		logger.With("shape", shape).With("count", count).With("caller", caller()).With("stack", string(debug.Stack())).
+			Warn("possible N+1 query, the same statement has run more than the threshold in one scope")
+	}
+
+	if db.NPlusOne.Strict {
+		return fmt.Errorf("%w: %q has run %d times", ErrNPlusOne, shape, count)
+	}
+	return nil
+}
@@ -0,0 +1,81 @@
+package gsdb
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalizeSQL(t *testing.T) {
+	tests := map[string]string{
+		"SELECT * FROM order_lines WHERE order_id=10;":                  "SELECT * FROM order_lines WHERE order_id=?",
+		"SELECT * FROM order_lines WHERE order_id = 11":                 "SELECT * FROM order_lines WHERE order_id = ?",
+		"SELECT * FROM t1 WHERE name='O''Brien' AND x=X'4142'":          "SELECT * FROM t1 WHERE name=? AND x=?",
+		"SELECT * FROM lines WHERE order_id IN (?,?, ?)":                "SELECT * FROM lines WHERE order_id IN (?)",
+		"SELECT *\n  FROM lines\n  WHERE id IN (1, 2, 3) AND price>1.5": "SELECT * FROM lines WHERE id IN (?) AND price>?",
+		"UPDATE t SET a=$1 WHERE id=$2":                                 "UPDATE t SET a=? WHERE id=?",
+	}
+	for in, expected := range tests {
+		assert.Equal(t, expected, NormalizeSQL(in), in)
+	}
+}
+
+func setupNPlusOne(t *testing.T, detector *NPlusOneDetector) *bytes.Buffer {
+	var logs bytes.Buffer
+	setupPreloadTables(t)
+	DB.Logger = slog.New(slog.NewTextHandler(&logs, nil))
+	DB.NPlusOne = detector
+	return &logs
+}
+
+func TestNPlusOneWarning(t *testing.T) {
+	logs := setupNPlusOne(t, &NPlusOneDetector{Threshold: 2})
+
+	ctx := WithQueryCounter(context.Background())
+	orders, err := QueryStructContext[PreloadOrder](ctx, "SELECT * FROM orders")
+	assert.NoError(t, err)
+	assert.Len(t, orders, 3)
+
+	for _, order := range orders {
+		_, err := QueryStructContext[PreloadLine](ctx, fmt.Sprintf("SELECT * FROM order_lines WHERE order_id=%d", order.Id))
+		assert.NoError(t, err)
+	}
+
+	out := logs.String()
+	assert.Contains(t, out, "possible N+1 query")
+	assert.Contains(t, out, `shape="SELECT * FROM order_lines WHERE order_id=?"`)
+	assert.Contains(t, out, "count=3")
+	assert.Contains(t, out, "nplusone_test.go")
+	assert.Equal(t, 1, bytes.Count(logs.Bytes(), []byte("possible N+1 query")))
+
+	// Preloading is a single query, so nothing is reported
+	logs.Reset()
+	ctx = WithQueryCounter(context.Background())
+	_, err = QueryStructContext[PreloadOrder](ctx, "SELECT * FROM orders", Preload("Lines"))
+	assert.NoError(t, err)
+	assert.Empty(t, logs.String())
+}
+
+func TestNPlusOneStrict(t *testing.T) {
+	setupNPlusOne(t, &NPlusOneDetector{Threshold: 2, Strict: true})
+
+	ctx := WithQueryCounter(context.Background())
+	for id := 10; id <= 12; id++ {
+		_, err := FindByPKContext[PreloadOrder](ctx, id)
+		if id < 12 {
+			assert.NoError(t, err)
+		} else {
+			assert.ErrorIs(t, err, ErrNPlusOne)
+		}
+	}
+
+	// Without a scope there's nothing to detect
+	for id := 10; id <= 12; id++ {
+		_, err := FindByPK[PreloadOrder](id)
+		assert.NoError(t, err)
+	}
+}
@@ -0,0 +1,103 @@
+package gsdb
+
+import (
+	"context"
+	"time"
+)
+
+// Hook is called around every statement gsdb runs: Execute, Query (and so QueryStruct, FindByPK and preloading)
+// and the BEGIN, COMMIT and ROLLBACK of WithTx. Register hooks with Database.AddHook before the Database is used.
+//
+// BeforeQuery can return a new context (e.g. one holding a span), which is passed to the statement and to
+// AfterQuery. An error from BeforeQuery stops the statement from running, and is returned to the caller.
+// AfterQuery is always called for every hook whose BeforeQuery was called, in reverse order.
+type Hook interface {
+	BeforeQuery(ctx context.Context, sql string, args []any) (context.Context, error)
+	AfterQuery(ctx context.Context, sql string, args []any, result HookResult, err error, duration time.Duration)
+}
+
+// Operation is the kind of statement a hook is called for
+type Operation string
+
+const (
+	OpExec     Operation = "exec"
+	OpQuery    Operation = "query"
+	OpBegin    Operation = "begin"
+	OpCommit   Operation = "commit"
+	OpRollback Operation = "rollback"
+)
+
+// HookResult is what AfterQuery is told about a statement. RowsAffected and LastInsertID are set for OpExec,
+// RowsReturned for OpQuery. Retry is 0 for the first attempt, 1 for the first retry (see RetryPolicy) and so on.
+type HookResult struct {
+	Operation    Operation
+	RowsAffected int64
+	LastInsertID int64
+	RowsReturned int64
+	Retry        int
+}
+
+// AddHook registers a hook. Hooks run in the order they were added.
+func (db *Database) AddHook(hook Hook) {
+	db.Hooks = append(db.Hooks, hook)
+}
+
+// context returns the Database context, for the functions that don't take one
+func (db *Database) context() context.Context {
+	if db == nil || db.Ctx == nil {
+		return context.Background()
+	}
+	return db.Ctx
+}
+
+// runHooks runs the statement in run, with the BeforeQuery hooks before it and the AfterQuery hooks after it.
+// conn is what the statement runs on (nil for BEGIN, COMMIT and ROLLBACK), for explaining slow queries.
+func (db *Database) runHooks(ctx context.Context, conn executor, operation Operation, sql string, args []any,
+	run func(ctx context.Context) (HookResult, error)) (HookResult, error) {
+
+	ctx, span := db.startSpan(ctx, "gsdb."+string(operation), sql)
+
+	var err error
+	ran := 0
+	for _, hook := range db.Hooks {
+		var next context.Context
+		if next, err = hook.BeforeQuery(ctx, sql, args); err != nil {
+			break
+		}
+		if next != nil {
+			ctx = next
+		}
+		ran++
+	}
+
+	if err == nil {
+		err = db.detectNPlusOne(ctx, operation, sql)
+	}
+
+	start := time.Now()
+	result := HookResult{Operation: operation, Retry: RetryCount(ctx)}
+	if err == nil {
+		result, err = run(ctx)
+		result.Operation = operation
+		result.Retry = RetryCount(ctx)
+
+		elapsed := time.Since(start)
+		countQuery(ctx, result, err, elapsed)
+		db.metrics.observe(sql, result, err, elapsed)
+		db.logSlowQuery(ctx, conn, operation, sql, args, elapsed)
+	}
+	duration := time.Since(start)
+
+	for i := ran - 1; i >= 0; i-- {
+		db.Hooks[i].AfterQuery(ctx, sql, args, result, err, duration)
+	}
+
+	switch operation {
+	case OpExec:
+		span.SetAttribute("db.rows_affected", result.RowsAffected)
+	case OpQuery:
+		span.SetAttribute("db.rows_returned", result.RowsReturned)
+	}
+	span.End(err)
+	return result, err
+}
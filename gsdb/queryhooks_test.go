@@ -0,0 +1,140 @@
+package gsdb
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+type hookCall struct {
+	sql    string
+	args   []any
+	result HookResult
+	err    error
+	value  any
+}
+
+type ctxKey string
+
+type QueryHookPerson struct {
+	Id   int    `db:"column=id primarykey=yes table=Users"`
+	Name string `db:"column=name"`
+}
+
+// recordingHook keeps every AfterQuery call, and can fail BeforeQuery
+type recordingHook struct {
+	calls     []hookCall
+	beforeErr error
+}
+
+func (h *recordingHook) BeforeQuery(ctx context.Context, sql string, args []any) (context.Context, error) {
+	if h.beforeErr != nil {
+		return ctx, h.beforeErr
+	}
+	return context.WithValue(ctx, ctxKey("hook"), sql), nil
+}
+
+func (h *recordingHook) AfterQuery(ctx context.Context, sql string, args []any, result HookResult, err error, duration time.Duration) {
+	h.calls = append(h.calls, hookCall{sql: sql, args: args, result: result, err: err, value: ctx.Value(ctxKey("hook"))})
+}
+
+func setupHookMock(t *testing.T) (sqlmock.Sqlmock, *recordingHook) {
+	New("test/test", slog.Default(), context.Background())
+	db, m, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	assert.NoError(t, err)
+	DB.dbConnection = db
+	DB.connected = true
+
+	hook := &recordingHook{}
+	DB.AddHook(hook)
+	return m, hook
+}
+
+func TestHooksExecuteAndQuery(t *testing.T) {
+	m, hook := setupHookMock(t)
+
+	m.ExpectExec("UPDATE Users SET status=1 WHERE id=?").WithArgs(7).WillReturnResult(sqlmock.NewResult(0, 1))
+	m.ExpectQuery("SELECT id, name FROM Users").WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(1, "Alice").AddRow(2, "Bob"))
+	m.ExpectQuery("SELECT id, name FROM Users WHERE id=?").WithArgs(2).WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(2, "Bob"))
+
+	_, _, err := DB.Execute("UPDATE Users SET status=1 WHERE id=?", 7)
+	assert.NoError(t, err)
+	_, err = DB.Query("SELECT id, name FROM Users")
+	assert.NoError(t, err)
+	_, err = QueryStruct[QueryHookPerson]("SELECT id, name FROM Users WHERE id=?", 2)
+	assert.NoError(t, err)
+	assert.NoError(t, m.ExpectationsWereMet())
+
+	if assert.Len(t, hook.calls, 3) {
+		assert.Equal(t, HookResult{Operation: OpExec, RowsAffected: 1}, hook.calls[0].result)
+		assert.Equal(t, []any{7}, hook.calls[0].args)
+		assert.Equal(t, "UPDATE Users SET status=1 WHERE id=?", hook.calls[0].value, "context from BeforeQuery is passed on")
+		assert.Equal(t, HookResult{Operation: OpQuery, RowsReturned: 2}, hook.calls[1].result)
+		assert.Equal(t, "SELECT id, name FROM Users WHERE id=?", hook.calls[2].sql)
+		assert.Equal(t, int64(1), hook.calls[2].result.RowsReturned)
+	}
+}
+
+func TestHooksErrors(t *testing.T) {
+	m, hook := setupHookMock(t)
+
+	failed := errors.New("failed")
+	m.ExpectExec("DELETE FROM Users").WillReturnError(failed)
+	_, _, err := DB.Execute("DELETE FROM Users")
+	assert.ErrorIs(t, err, failed)
+	if assert.Len(t, hook.calls, 1) {
+		assert.ErrorIs(t, hook.calls[0].err, failed)
+	}
+
+	// An error from BeforeQuery stops the statement
+	hook.beforeErr = errors.New("not allowed")
+	_, err = DB.Query("SELECT * FROM Users")
+	assert.EqualError(t, err, "not allowed")
+	assert.NoError(t, m.ExpectationsWereMet())
+	assert.Len(t, hook.calls, 1, "AfterQuery is not called when the hook's own BeforeQuery failed")
+}
+
+func TestHooksWithTx(t *testing.T) {
+	m, hook := setupHookMock(t)
+
+	m.ExpectBegin()
+	m.ExpectExec("UPDATE Users SET status=2").WillReturnResult(sqlmock.NewResult(0, 3))
+	m.ExpectQuery("SELECT id, name FROM Users").WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(1, "Alice"))
+	m.ExpectCommit()
+
+	err := DB.WithTx(context.Background(), func(tx *Tx) error {
+		if _, _, err := tx.Execute("UPDATE Users SET status=2"); err != nil {
+			return err
+		}
+		people, err := QueryStructTx[QueryHookPerson](tx, "SELECT id, name FROM Users")
+		assert.Len(t, people, 1)
+		return err
+	})
+	assert.NoError(t, err)
+	assert.NoError(t, m.ExpectationsWereMet())
+
+	operations := make([]Operation, 0)
+	for _, c := range hook.calls {
+		operations = append(operations, c.result.Operation)
+	}
+	assert.Equal(t, []Operation{OpBegin, OpExec, OpQuery, OpCommit}, operations)
+
+	// An error from the closure rolls back
+	hook.calls = nil
+	m.ExpectBegin()
+	m.ExpectRollback()
+	failed := errors.New("failed")
+	err = DB.WithTx(context.Background(), func(tx *Tx) error {
+		return failed
+	})
+	assert.ErrorIs(t, err, failed)
+	assert.NoError(t, m.ExpectationsWereMet())
+	if assert.Len(t, hook.calls, 2) {
+		assert.Equal(t, "ROLLBACK", hook.calls[1].sql)
+	}
+}
@@ -0,0 +1,188 @@
+package gsdb
+
+import (
+	"encoding/hex"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// While a statement is built, the values of fields tagged sensitive=yes (and Sensitive Record values) are marked
+// with this comment. The marks are taken out before the statement is returned, and the marked statement is kept
+// by the Database, so the values can be found and masked if the statement is later logged or fails.
+const sensitiveMarker = "/*sensitive*/"
+
+// redactedValue is what a sensitive value is replaced with
+const redactedValue = "'[REDACTED]'"
+
+var sensitiveLiteral = regexp.MustCompile(`/\*sensitive\*/(X'[0-9a-fA-F]*'|'(?:[^']|'')*'|[^,)\s;]+)`)
+
+// sensitiveStatementLimit is the number of statements with sensitive values a Database remembers. Statements are
+// normally run soon after they're built, so only the most recent are kept.
+const sensitiveStatementLimit = 1000
+
+// sensitiveStatements maps the statements built with sensitive values to their marked form
+type sensitiveStatements struct {
+	lock   sync.Mutex
+	marked map[string]string
+	order  []string
+}
+
+// Sensitive wraps a Record value to keep it out of logs and error messages when the Record is written by
+// RecordInsert or RecordUpdate, like the sensitive=yes tag:
+//
+//	gsdb.Record{"email": gsdb.Field{Value: gsdb.Sensitive{Value: "bob@example.com"}}}
+type Sensitive struct {
+	Value any
+}
+
+// String hides the value, so a Sensitive value is safe to print
+func (s Sensitive) String() string {
+	return "[REDACTED]"
+}
+
+// Redactor rewrites a statement before it is logged by ShowSQL or the slow query log. The sensitive values are
+// always masked first (see MaskSensitive), the Redactor can then hide more. To log only the shape of statements,
+// with every value stripped, use NormalizeSQL:
+//
+//	gsdb.DB.Redactor = gsdb.NormalizeSQL
+type Redactor func(sql string) string
+
+// MaskSensitive replaces the values of sensitive fields in a statement built by DB with '[REDACTED]'
+func MaskSensitive(sql string) string {
+	return DB.maskSensitive(sql)
+}
+
+func (db *Database) maskSensitive(sql string) string {
+	marked := db.markedStatement(sql)
+	if !strings.Contains(marked, sensitiveMarker) {
+		return sql
+	}
+	return sensitiveLiteral.ReplaceAllString(marked, redactedValue)
+}
+
+// unmark takes the sensitive marks out of a statement that has been built, remembering where they were
+func (db *Database) unmark(marked string) string {
+
+	if !strings.Contains(marked, sensitiveMarker) {
+		return marked
+	}
+	sql := strings.ReplaceAll(marked, sensitiveMarker, "")
+	if db == nil {
+		return sql
+	}
+
+	s := &db.sensitive
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if s.marked == nil {
+		s.marked = make(map[string]string)
+	}
+	if _, ok := s.marked[sql]; !ok {
+		s.order = append(s.order, sql)
+		if len(s.order) > sensitiveStatementLimit {
+			delete(s.marked, s.order[0])
+			s.order = s.order[1:]
+		}
+	}
+	s.marked[sql] = marked
+	return sql
+}
+
+// markedStatement returns a statement with its sensitive values marked, if the Database built it
+func (db *Database) markedStatement(sql string) string {
+	if db == nil {
+		return sql
+	}
+	db.sensitive.lock.Lock()
+	defer db.sensitive.lock.Unlock()
+	if marked, ok := db.sensitive.marked[sql]; ok {
+		return marked
+	}
+	return sql
+}
+
+// redact prepares a statement for logging
+func (db *Database) redact(sql string) string {
+	sql = db.maskSensitive(sql)
+	if db != nil && db.Redactor != nil {
+		sql = db.Redactor(sql)
+	}
+	return sql
+}
+
+// redactError masks the sensitive values of the statement in an error message, as drivers often quote the
+// value (e.g. Duplicate entry 'bob@example.com' for key 'email'). Values shorter than 4 characters are only
+// masked where they're quoted ('123'), so they don't mangle the rest of the message (error numbers and so on).
+// The original error can still be reached with errors.Is and errors.As.
+func (db *Database) redactError(err error, sql string) error {
+
+	if err == nil {
+		return err
+	}
+	sql = db.markedStatement(sql)
+	if !strings.Contains(sql, sensitiveMarker) {
+		return err
+	}
+
+	message := err.Error()
+	for _, match := range sensitiveLiteral.FindAllStringSubmatch(sql, -1) {
+		for _, value := range []string{match[1], literalValue(match[1])} {
+			switch {
+			case value == "":
+			case len(value) >= 4:
+				message = strings.ReplaceAll(message, value, "[REDACTED]")
+			default:
+				message = strings.ReplaceAll(message, "'"+value+"'", redactedValue)
+			}
+		}
+	}
+	if message == err.Error() {
+		return err
+	}
+	return &redactedError{message: message, err: err}
+}
+
+// literalValue decodes an SQL literal written by gsdb back to the value it holds
+func literalValue(literal string) string {
+	switch {
+	case strings.HasPrefix(literal, "X'") || strings.HasPrefix(literal, "x'"):
+		b, err := hex.DecodeString(literal[2 : len(literal)-1])
+		if err != nil {
+			return ""
+		}
+		return string(b)
+	case strings.HasPrefix(literal, "'"):
+		return strings.ReplaceAll(literal[1:len(literal)-1], "''", "'")
+	}
+	return literal
+}
+
+// logValue is a value as it should be logged, which is masked when it's sensitive
+func logValue(value any, sensitive bool) any {
+	if sensitive {
+		return "[REDACTED]"
+	}
+	return value
+}
+
+// recordValue unwraps a Record value, reporting whether it is Sensitive
+func recordValue(F Field) (value any, sensitive bool) {
+	if s, ok := F.Value.(Sensitive); ok {
+		return s.Value, true
+	}
+	return F.Value, false
+}
+
+type redactedError struct {
+	message string
+	err     error
+}
+
+func (e *redactedError) Error() string {
+	return e.message
+}
+
+func (e *redactedError) Unwrap() error {
+	return e.err
+}
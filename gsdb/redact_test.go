@@ -0,0 +1,128 @@
+package gsdb
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type SensitivePerson struct {
+	Id    int    `db:"column=id primarykey=yes table=people"`
+	Name  string `db:"column=name"`
+	Email string `db:"column=email sensitive=yes"`
+	Age   int    `db:"column=age sensitive=yes"`
+}
+
+func TestMaskSensitive(t *testing.T) {
+	sql, err := DB.Insert(SensitivePerson{Name: "Bob", Email: "bob@example.com", Age: 42})
+	assert.NoError(t, err)
+	assert.Equal(t, "INSERT INTO people(name,email,age) VALUES (X'426f62',X'626f62406578616d706c652e636f6d',42);", sql)
+	assert.Equal(t, "INSERT INTO people(name,email,age) VALUES (X'426f62','[REDACTED]','[REDACTED]');", MaskSensitive(sql))
+
+	sql, err = DB.Update(SensitivePerson{Id: 3, Name: "Bob", Email: "bob@example.com", Age: 42})
+	assert.NoError(t, err)
+	assert.Equal(t, "UPDATE people SET name=X'426f62',email=X'626f62406578616d706c652e636f6d',age=42 WHERE id=3;", sql)
+	assert.Equal(t, "UPDATE people SET name=X'426f62',email='[REDACTED]',age='[REDACTED]' WHERE id=3;", MaskSensitive(sql))
+
+	// Only the values of that statement are masked, the same value elsewhere isn't
+	assert.Equal(t, "UPDATE people SET age=42 WHERE id=3;", MaskSensitive("UPDATE people SET age=42 WHERE id=3;"))
+	other := &Database{}
+	assert.Equal(t, sql, other.maskSensitive(sql), "only the Database that built the statement knows its values")
+
+	assert.Equal(t, "SELECT 'it''s' FROM x", MaskSensitive("SELECT 'it''s' FROM x"))
+	assert.Equal(t, "x='[REDACTED]';", MaskSensitive(DB.unmark("x=/*sensitive*/'it''s';")))
+}
+
+func setupRedactLog(t *testing.T) *bytes.Buffer {
+	var logs bytes.Buffer
+	NewSQLite3("test.db", slog.New(slog.NewTextHandler(&logs, nil)), context.Background())
+	_, _, err := DB.Execute("DROP TABLE IF EXISTS people;")
+	assert.NoError(t, err)
+	_, _, err = DB.Execute("CREATE TABLE people (id INTEGER PRIMARY KEY, name TEXT, email TEXT UNIQUE, age INTEGER);")
+	assert.NoError(t, err)
+	logs.Reset()
+	return &logs
+}
+
+func TestRedactShowSQL(t *testing.T) {
+	logs := setupRedactLog(t)
+	ShowSQL = true
+	defer func() { ShowSQL = false }()
+
+	_, _, err := DB.Save(SensitivePerson{Name: "Bob", Email: "bob@example.com", Age: 42}, 0)
+	assert.NoError(t, err)
+	assert.Contains(t, logs.String(), `'[REDACTED]','[REDACTED]'`)
+	assert.NotContains(t, logs.String(), "626f62406578616d706c652e636f6d")
+
+	// The value is still written
+	person, err := FindByPK[SensitivePerson](1)
+	assert.NoError(t, err)
+	assert.Equal(t, "bob@example.com", person.Email)
+	assert.Equal(t, 42, person.Age)
+
+	// Only the shapes of statements
+	logs.Reset()
+	DB.Redactor = NormalizeSQL
+	_, err = DB.RecordInsert(Record{"email": Field{Value: Sensitive{Value: "alice@example.com"}}}, "people")
+	assert.NoError(t, err)
+	assert.Contains(t, logs.String(), `msg="INSERT INTO people(email) VALUES (?)"`)
+}
+
+func TestRedactSlowQuery(t *testing.T) {
+	logs := setupRedactLog(t)
+	DB.SlowQueryThreshold = time.Nanosecond
+
+	_, err := DB.RecordUpdate(Record{"email": Field{Value: Sensitive{Value: "carol@example.com"}}}, "people", "id", "1")
+	assert.NoError(t, err)
+	assert.Contains(t, logs.String(), `sql="UPDATE people SET email = '[REDACTED]' WHERE id = 1"`)
+	assert.NotContains(t, logs.String(), "6361726f6c406578616d706c652e636f6d")
+}
+
+func TestRedactError(t *testing.T) {
+	m, _ := setupHookMock(t)
+
+	duplicate := errors.New("Error 1062 (23000): Duplicate entry 'bob@example.com' for key 'email'")
+	m.ExpectExec("INSERT INTO people(name,email,age) VALUES (X'426f62',X'626f62406578616d706c652e636f6d',42);").
+		WillReturnError(duplicate)
+
+	_, _, err := DB.Save(SensitivePerson{Name: "Bob", Email: "bob@example.com", Age: 42}, 0)
+	assert.EqualError(t, err, "Error 1062 (23000): Duplicate entry '[REDACTED]' for key 'email'")
+	assert.ErrorIs(t, err, duplicate)
+
+	// Errors that don't mention the values are left alone
+	other := errors.New("connection refused")
+	m.ExpectExec("UPDATE people SET name=X'426f62',email=X'',age=0 WHERE id=1;").WillReturnError(other)
+	_, _, err = DB.Save(SensitivePerson{Id: 1, Name: "Bob"}, 1)
+	assert.EqualError(t, err, "connection refused")
+	assert.ErrorIs(t, err, other)
+	assert.NoError(t, m.ExpectationsWereMet())
+}
+
+func TestRedactErrorShortValues(t *testing.T) {
+	sql := DB.unmark("INSERT INTO people(name,pin,age) VALUES (X'426f62',/*sensitive*/X'313233',/*sensitive*/42);")
+
+	err := DB.redactError(errors.New("Error 1062 (23000): Duplicate entry '123' for key 'pin'"), sql)
+	assert.EqualError(t, err, "Error 1062 (23000): Duplicate entry '[REDACTED]' for key 'pin'")
+
+	err = DB.redactError(errors.New("Error 1264 (22003): Out of range value '42' for column 'age' at row 1"), sql)
+	assert.EqualError(t, err, "Error 1264 (22003): Out of range value '[REDACTED]' for column 'age' at row 1")
+}
+
+func TestSensitiveStatementLimit(t *testing.T) {
+	db := &Database{}
+	first := db.unmark("UPDATE people SET email=/*sensitive*/X'00' WHERE id=0;")
+	for i := 1; i <= sensitiveStatementLimit; i++ {
+		db.unmark(fmt.Sprintf("UPDATE people SET email=/*sensitive*/X'00' WHERE id=%d;", i))
+	}
+
+	// Only the most recent statements are remembered
+	assert.Equal(t, first, db.maskSensitive(first))
+	assert.Equal(t, "UPDATE people SET email='[REDACTED]' WHERE id=1;", db.maskSensitive("UPDATE people SET email=X'00' WHERE id=1;"))
+	assert.Len(t, db.sensitive.marked, sensitiveStatementLimit)
+}
@@ -0,0 +1,171 @@
+package gsdb
+
+import (
+	"context"
+	"math/rand/v2"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// RetryPolicy retries statements that failed for a reason that is likely to go away, such as a deadlock or a lock
+// wait timeout. It applies to Execute, to reads (SELECT, WITH ... SELECT, SHOW and EXPLAIN) run with Query,
+// QueryResult, QueryStruct and so on, and to whole WithTx closures. Statements run inside a transaction aren't
+// retried on their own, as the database has usually rolled back the transaction; the WithTx closure is run again
+// instead, so it must be safe to repeat.
+//
+// The delay before each retry doubles from InitialBackoff up to MaxBackoff, and is randomised between half and the
+// full value so that clients that collided don't collide again. Zero values take the DefaultRetryPolicy values.
+//
+//	gsdb.DB.Retry = gsdb.DefaultRetryPolicy()
+//
+// Hooks can tell a retry from the first attempt with HookResult.Retry or RetryCount.
+type RetryPolicy struct {
+	MaxAttempts    int                // attempts in total, including the first
+	InitialBackoff time.Duration      // delay before the first retry
+	MaxBackoff     time.Duration      // longest delay between attempts
+	RetryOn        []func(error) bool // the error classes to retry, e.g. IsDeadlock
+}
+
+// DefaultRetryPolicy makes 3 attempts, 50ms then 100ms apart (before the jitter), for deadlocks and lock timeouts.
+// IsConnectionLost isn't included, as a write may have been applied before the connection went.
+func DefaultRetryPolicy() *RetryPolicy {
+	return &RetryPolicy{
+		MaxAttempts:    3,
+		InitialBackoff: 50 * time.Millisecond,
+		MaxBackoff:     time.Second,
+		RetryOn:        []func(error) bool{IsDeadlock, IsLockTimeout},
+	}
+}
+
+func (p *RetryPolicy) maxAttempts() int {
+	if p.MaxAttempts <= 0 {
+		return DefaultRetryPolicy().MaxAttempts
+	}
+	return p.MaxAttempts
+}
+
+// retryable reports whether err is in one of the error classes of the policy
+func (p *RetryPolicy) retryable(err error) bool {
+	retryOn := p.RetryOn
+	if len(retryOn) == 0 {
+		retryOn = DefaultRetryPolicy().RetryOn
+	}
+	for _, class := range retryOn {
+		if class(err) {
+			return true
+		}
+	}
+	return false
+}
+
+// backoff is the delay before the given retry (starting at 1)
+func (p *RetryPolicy) backoff(retry int) time.Duration {
+
+	defaults := DefaultRetryPolicy()
+	delay, limit := p.InitialBackoff, p.MaxBackoff
+	if delay <= 0 {
+		delay = defaults.InitialBackoff
+	}
+	if limit <= 0 {
+		limit = defaults.MaxBackoff
+	}
+
+	for i := 1; i < retry && delay < limit; i++ {
+		delay *= 2
+	}
+	if delay > limit {
+		delay = limit
+	}
+	return delay/2 + rand.N(delay/2+1)
+}
+
+type retryCountKey struct{}
+
+// RetryCount returns how many times the statement (or transaction) in the context has been retried, 0 for the
+// first attempt
+func RetryCount(ctx context.Context) int {
+	retry, _ := ctx.Value(retryCountKey{}).(int)
+	return retry
+}
+
+// retry runs fn until it succeeds, fails with an error the policy doesn't retry, runs out of attempts or the
+// context is done. The last error is returned.
+func (db *Database) retry(ctx context.Context, fn func(ctx context.Context) error) error {
+
+	policy := db.Retry
+	if policy == nil {
+		return fn(ctx)
+	}
+
+	for retry := 0; ; retry++ {
+		attemptCtx := ctx
+		if retry > 0 {
+			attemptCtx = context.WithValue(ctx, retryCountKey{}, retry)
+		}
+
+		err := fn(attemptCtx)
+		if err == nil || retry+1 >= policy.maxAttempts() || !policy.retryable(err) {
+			return err
+		}
+
+		delay := policy.backoff(retry + 1)
+		db.Logger.With("attempt", retry+1).With("delay", delay).With("error", err.Error()).Warn("Retrying after a transient database error")
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		case <-timer.C:
+		}
+	}
+}
+
+// readOnly reports whether a statement only reads, so it's safe to run again. A WITH (common table expression)
+// counts if the statement it leads to is a SELECT.
+func readOnly(sql string) bool {
+	operation, _ := statementLabels(sql)
+	switch operation {
+	case "select", "show", "explain", "describe", "desc":
+		return true
+	case "with":
+		return cteStatement(sql) == "select"
+	}
+	return false
+}
+
+// cteStatement returns the first keyword of the statement after the WITH ... AS (...) definitions, looking only
+// outside brackets and quotes
+func cteStatement(sql string) string {
+
+	depth := 0
+	var quote rune
+	word := strings.Builder{}
+
+	for _, r := range sql + " " {
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			}
+			continue
+		case r == '\'' || r == '"' || r == '`':
+			quote = r
+		case r == '(':
+			depth++
+		case r == ')':
+			depth--
+		case depth == 0 && (unicode.IsLetter(r) || r == '_'):
+			word.WriteRune(r)
+			continue
+		}
+
+		switch w := strings.ToLower(word.String()); w {
+		case "select", "insert", "update", "delete", "replace":
+			return w
+		}
+		word.Reset()
+	}
+	return ""
+}
@@ -0,0 +1,184 @@
+package gsdb
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/go-sql-driver/mysql"
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	deadlockError    = &mysql.MySQLError{Number: 1213, Message: "Deadlock found when trying to get lock"}
+	lockTimeoutError = &mysql.MySQLError{Number: 1205, Message: "Lock wait timeout exceeded"}
+	duplicateError   = &mysql.MySQLError{Number: 1062, Message: "Duplicate entry '1' for key 'PRIMARY'"}
+)
+
+func setupRetryMock(t *testing.T) (sqlmock.Sqlmock, *recordingHook) {
+	m, hook := setupHookMock(t)
+	DB.Retry = &RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Millisecond, MaxBackoff: 2 * time.Millisecond}
+	return m, hook
+}
+
+func retries(hook *recordingHook) []int {
+	out := make([]int, 0)
+	for _, c := range hook.calls {
+		out = append(out, c.result.Retry)
+	}
+	return out
+}
+
+func TestRetryExecute(t *testing.T) {
+	m, hook := setupRetryMock(t)
+
+	m.ExpectExec("UPDATE Users SET status=1 WHERE id=?").WithArgs(7).WillReturnError(deadlockError)
+	m.ExpectExec("UPDATE Users SET status=1 WHERE id=?").WithArgs(7).WillReturnError(lockTimeoutError)
+	m.ExpectExec("UPDATE Users SET status=1 WHERE id=?").WithArgs(7).WillReturnResult(sqlmock.NewResult(0, 1))
+
+	_, rows, err := DB.Execute("UPDATE Users SET status=1 WHERE id=?", 7)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), rows)
+	assert.Equal(t, []int{0, 1, 2}, retries(hook))
+	assert.NoError(t, m.ExpectationsWereMet())
+
+	// Gives up after MaxAttempts, with the last error
+	hook.calls = nil
+	for i := 0; i < 3; i++ {
+		m.ExpectExec("UPDATE Users SET status=2").WillReturnError(deadlockError)
+	}
+	_, _, err = DB.Execute("UPDATE Users SET status=2")
+	assert.True(t, IsDeadlock(err))
+	assert.Equal(t, []int{0, 1, 2}, retries(hook))
+	assert.NoError(t, m.ExpectationsWereMet())
+
+	// Errors outside the retried classes come straight back
+	hook.calls = nil
+	m.ExpectExec("INSERT INTO Users (id) VALUES (1)").WillReturnError(duplicateError)
+	_, _, err = DB.Execute("INSERT INTO Users (id) VALUES (1)")
+	assert.True(t, IsDuplicateKey(err))
+	assert.Len(t, hook.calls, 1)
+	assert.NoError(t, m.ExpectationsWereMet())
+}
+
+func TestRetryQuery(t *testing.T) {
+	m, hook := setupRetryMock(t)
+	DB.Retry.RetryOn = []func(error) bool{IsLockTimeout}
+
+	m.ExpectQuery("SELECT id, name FROM Users").WillReturnError(lockTimeoutError)
+	m.ExpectQuery("SELECT id, name FROM Users").WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(1, "Alice"))
+
+	people, err := QueryStruct[QueryHookPerson]("SELECT id, name FROM Users")
+	assert.NoError(t, err)
+	assert.Len(t, people, 1)
+	assert.Equal(t, []int{0, 1}, retries(hook))
+	assert.NoError(t, m.ExpectationsWereMet())
+
+	// Only the configured classes are retried
+	hook.calls = nil
+	m.ExpectQuery("SELECT id, name FROM Users").WillReturnError(deadlockError)
+	_, err = DB.Query("SELECT id, name FROM Users")
+	assert.True(t, IsDeadlock(err))
+	assert.Len(t, hook.calls, 1)
+	assert.NoError(t, m.ExpectationsWereMet())
+
+	// Statements that write aren't retried through Query
+	hook.calls = nil
+	m.ExpectQuery("INSERT INTO Users (name) VALUES ('Bob') RETURNING id").WillReturnError(lockTimeoutError)
+	_, err = DB.Query("INSERT INTO Users (name) VALUES ('Bob') RETURNING id")
+	assert.True(t, IsLockTimeout(err))
+	assert.Len(t, hook.calls, 1)
+	assert.NoError(t, m.ExpectationsWereMet())
+}
+
+func TestRetryWithTx(t *testing.T) {
+	m, hook := setupRetryMock(t)
+
+	m.ExpectBegin()
+	m.ExpectExec("UPDATE Users SET status=2").WillReturnError(deadlockError)
+	m.ExpectRollback()
+	m.ExpectBegin()
+	m.ExpectExec("UPDATE Users SET status=2").WillReturnResult(sqlmock.NewResult(0, 3))
+	m.ExpectCommit()
+
+	runs := 0
+	err := DB.WithTx(context.Background(), func(tx *Tx) error {
+		runs++
+		_, _, err := tx.Execute("UPDATE Users SET status=2")
+		return err
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 2, runs)
+	assert.NoError(t, m.ExpectationsWereMet())
+
+	operations := make([]Operation, 0)
+	for _, c := range hook.calls {
+		operations = append(operations, c.result.Operation)
+	}
+	assert.Equal(t, []Operation{OpBegin, OpExec, OpRollback, OpBegin, OpExec, OpCommit}, operations)
+	assert.Equal(t, []int{0, 0, 0, 1, 1, 1}, retries(hook))
+}
+
+func TestRetryDisabled(t *testing.T) {
+	m, hook := setupHookMock(t)
+
+	m.ExpectExec("UPDATE Users SET status=1").WillReturnError(deadlockError)
+	_, _, err := DB.Execute("UPDATE Users SET status=1")
+	assert.True(t, IsDeadlock(err))
+	assert.Len(t, hook.calls, 1)
+	assert.NoError(t, m.ExpectationsWereMet())
+}
+
+func TestRetryContextDone(t *testing.T) {
+	m, hook := setupHookMock(t)
+	DB.Retry = &RetryPolicy{MaxAttempts: 5, InitialBackoff: time.Hour}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	m.ExpectExec("UPDATE Users SET status=1").WillReturnError(deadlockError)
+	_, _, err := DB.ExecuteContext(ctx, "UPDATE Users SET status=1")
+	assert.True(t, IsDeadlock(err))
+	assert.Len(t, hook.calls, 1)
+	assert.NoError(t, m.ExpectationsWereMet())
+}
+
+func TestRetryBackoff(t *testing.T) {
+	policy := &RetryPolicy{InitialBackoff: 100 * time.Millisecond, MaxBackoff: 300 * time.Millisecond}
+
+	for i := 0; i < 50; i++ {
+		delay := policy.backoff(1)
+		assert.GreaterOrEqual(t, delay, 50*time.Millisecond)
+		assert.LessOrEqual(t, delay, 100*time.Millisecond)
+
+		delay = policy.backoff(2)
+		assert.GreaterOrEqual(t, delay, 100*time.Millisecond)
+		assert.LessOrEqual(t, delay, 200*time.Millisecond)
+
+		delay = policy.backoff(10)
+		assert.GreaterOrEqual(t, delay, 150*time.Millisecond)
+		assert.LessOrEqual(t, delay, 300*time.Millisecond)
+	}
+
+	assert.Equal(t, 3, (&RetryPolicy{}).maxAttempts())
+	assert.True(t, (&RetryPolicy{}).retryable(deadlockError))
+	assert.False(t, (&RetryPolicy{}).retryable(duplicateError))
+	assert.Equal(t, 0, RetryCount(context.Background()))
+}
+
+func TestRetryReadOnly(t *testing.T) {
+	tests := map[string]bool{
+		"SELECT * FROM Users": true,
+		"  show tables":       true,
+		"WITH recent AS (SELECT * FROM orders WHERE id > 10) SELECT * FROM recent":                  true,
+		"with recursive n(i) as (select 1 union all select i+1 from n) select i from n":             true,
+		"WITH gone AS (SELECT id FROM orders) DELETE FROM orders WHERE id IN (SELECT id FROM gone)": false,
+		"WITH x AS (SELECT ')' AS p) UPDATE Users SET name='select'":                                false,
+		"INSERT INTO Users (name) VALUES ('Bob') RETURNING id":                                      false,
+		"UPDATE Users SET status=1": false,
+	}
+	for sql, want := range tests {
+		assert.Equal(t, want, readOnly(sql), sql)
+	}
+}
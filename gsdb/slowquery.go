@@ -0,0 +1,99 @@
+package gsdb
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"reflect"
+	"runtime"
+	"strings"
+	"time"
+)
+
+// packagePath is used to find the first caller outside gsdb
+var packagePath = reflect.TypeOf(Database{}).PkgPath()
+
+// logSlowQuery logs a statement that took longer than the SlowQueryThreshold, with where it was called from and,
+// if ExplainSlowQueries is set, the query plan. The arguments are logged as their types only.
+func (db *Database) logSlowQuery(ctx context.Context, conn executor, operation Operation, sql string, args []any, duration time.Duration) {
+
+	if db.SlowQueryThreshold <= 0 || duration < db.SlowQueryThreshold || (operation != OpExec && operation != OpQuery) {
+		return
+	}
+
+	logger := db.Logger
+	if logger == nil {
+		logger = slog.Default()
+	}
+	logger = logger.With("sql", db.redact(sql)).With("args", redactArgs(args)).With("duration", duration).With("caller", caller())
+
+	if db.ExplainSlowQueries {
+		plan, err := db.explain(ctx, conn, sql, args)
+		if err != nil {
+			logger = logger.With("explainError", db.redactError(err, sql).Error())
+		} else {
+			logger = logger.With("plan", plan)
+		}
+	}
+	logger.Warn("slow query")
+}
+
+// explain runs EXPLAIN (MySQL) or EXPLAIN QUERY PLAN (SQLite) on the statement, straight on conn so it isn't seen
+// by the hooks or counted. conn is what the statement ran on, so a statement in a transaction is explained inside
+// it. Each row of the plan is a line, with the columns separated by |.
+func (db *Database) explain(ctx context.Context, conn executor, sql string, args []any) (string, error) {
+
+	if conn == nil {
+		return "", fmt.Errorf("not connected")
+	}
+
+	explain := "EXPLAIN " + sql
+	if db.sqlite() {
+		explain = "EXPLAIN QUERY PLAN " + sql
+	}
+
+	var rs ResultSet
+	if err := db.scanResult(ctx, conn, &rs, explain, args...); err != nil {
+		return "", err
+	}
+
+	lines := []string{strings.Join(rs.ColumnNames(), " | ")}
+	for _, row := range rs.Rows {
+		values := make([]string, 0, len(row))
+		for _, f := range row {
+			values = append(values, f.AsString())
+		}
+		lines = append(lines, strings.Join(values, " | "))
+	}
+	return strings.Join(lines, "\n"), nil
+}
+
+// redactArgs describes the arguments of a statement by type, so values never reach the logs
+func redactArgs(args []any) []string {
+	redacted := make([]string, 0, len(args))
+	for _, arg := range args {
+		if arg == nil {
+			redacted = append(redacted, "NULL")
+			continue
+		}
+		redacted = append(redacted, fmt.Sprintf("%T", arg))
+	}
+	return redacted
+}
+
+// caller returns the file:line of the first function outside gsdb in the call stack. Tests of gsdb itself count as
+// outside.
+func caller() string {
+
+	pcs := make([]uintptr, 32)
+	frames := runtime.CallersFrames(pcs[:runtime.Callers(2, pcs)])
+	for {
+		frame, more := frames.Next()
+		if !strings.HasPrefix(frame.Function, packagePath+".") || strings.HasSuffix(frame.File, "_test.go") {
+			return fmt.Sprintf("%s:%d", frame.File, frame.Line)
+		}
+		if !more {
+			return ""
+		}
+	}
+}
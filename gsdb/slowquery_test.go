@@ -0,0 +1,83 @@
+package gsdb
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func setupSlowQueryLog(t *testing.T, threshold time.Duration) *bytes.Buffer {
+	var logs bytes.Buffer
+	NewSQLite3("test.db", slog.New(slog.NewTextHandler(&logs, nil)), context.Background())
+	DB.SlowQueryThreshold = threshold
+	DB.ExplainSlowQueries = true
+
+	_, _, err := DB.Execute("DROP TABLE IF EXISTS slow;")
+	assert.NoError(t, err)
+	_, _, err = DB.Execute("CREATE TABLE slow (id INTEGER PRIMARY KEY, name TEXT);")
+	assert.NoError(t, err)
+	logs.Reset()
+	return &logs
+}
+
+func TestSlowQueryLog(t *testing.T) {
+	logs := setupSlowQueryLog(t, time.Nanosecond)
+
+	_, err := DB.Query("SELECT * FROM slow WHERE name=?", "secret")
+	assert.NoError(t, err)
+
+	out := logs.String()
+	assert.Contains(t, out, `msg="slow query"`)
+	assert.Contains(t, out, `sql="SELECT * FROM slow WHERE name=?"`)
+	assert.Contains(t, out, "args=[string]")
+	assert.NotContains(t, out, "secret")
+	assert.Contains(t, out, "slowquery_test.go:")
+	assert.Contains(t, out, "SCAN slow")
+}
+
+func TestSlowQueryThreshold(t *testing.T) {
+	logs := setupSlowQueryLog(t, time.Hour)
+
+	_, err := DB.Query("SELECT * FROM slow")
+	assert.NoError(t, err)
+	assert.Empty(t, logs.String())
+
+	DB.SlowQueryThreshold = 0
+	_, err = DB.Query("SELECT * FROM slow")
+	assert.NoError(t, err)
+	assert.Empty(t, logs.String(), "no threshold, no slow query log")
+}
+
+func TestSlowQueryExplainError(t *testing.T) {
+	logs := setupSlowQueryLog(t, time.Nanosecond)
+
+	_, err := DB.Query("SELECT * FROM missing")
+	assert.Error(t, err)
+	assert.Contains(t, logs.String(), "explainError=")
+}
+
+func TestSlowQueryExplainInTx(t *testing.T) {
+	logs := setupSlowQueryLog(t, time.Nanosecond)
+
+	// The table only exists inside the transaction, so the plan has to be asked for there
+	err := DB.WithTx(context.Background(), func(tx *Tx) error {
+		if _, _, err := tx.Execute("CREATE TABLE tx_only (id INTEGER PRIMARY KEY, name TEXT);"); err != nil {
+			return err
+		}
+		logs.Reset()
+		_, err := tx.Query("SELECT * FROM tx_only WHERE name=?", "secret")
+		return err
+	})
+	assert.NoError(t, err)
+
+	out := logs.String()
+	assert.Contains(t, out, "SCAN tx_only")
+	assert.NotContains(t, out, "explainError=")
+
+	_, _, err = DB.Execute("DROP TABLE IF EXISTS tx_only;")
+	assert.NoError(t, err)
+}
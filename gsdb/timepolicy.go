@@ -0,0 +1,74 @@
+package gsdb
+
+import (
+	"strings"
+	"time"
+)
+
+// TimePolicy controls how times are written to and read from the database. The zero value keeps the
+// original behaviour: times are written as they are, to the second, and strings are read back as UTC.
+type TimePolicy struct {
+	// Location the database stores times in, e.g. time.UTC. Times are converted to it before being written,
+	// and values read back are taken to be wall clock times in it. nil writes times unconverted and reads as UTC.
+	Location *time.Location
+	// Precision is the number of fractional second digits written, 0 to 6 (use 6 for DATETIME(6)). Higher values
+	// are written as 6, the most MySQL stores.
+	Precision int
+	// ReadLocation is the location times are converted to after being read. nil leaves them in Location.
+	ReadLocation *time.Location
+}
+
+const timeLayout = "2006-01-02 15:04:05"
+
+// formatTime formats a time for an SQL statement, following the Database TimePolicy
+func (db *Database) formatTime(t time.Time) string {
+
+	var policy TimePolicy
+	if db != nil {
+		policy = db.TimePolicy
+	}
+
+	if policy.Location != nil {
+		t = t.In(policy.Location)
+	}
+
+	layout := timeLayout
+	if policy.Precision > 0 {
+		layout = layout + "." + strings.Repeat("0", min(policy.Precision, 6))
+	}
+	return t.Format(layout)
+}
+
+// parseTime parses a time read from the database as a string with the layout, following the Database TimePolicy.
+// Fractional seconds are accepted whatever the precision.
+func (db *Database) parseTime(layout string, s string) (time.Time, error) {
+
+	location := time.UTC
+	if db != nil && db.TimePolicy.Location != nil {
+		location = db.TimePolicy.Location
+	}
+
+	t, err := time.ParseInLocation(layout, s, location)
+	if err != nil {
+		return t, err
+	}
+	return db.readLocation(t), nil
+}
+
+// readTime applies the TimePolicy to a time.Time returned by the driver. When a storage Location is set, the
+// wall clock is taken to be in it, as drivers label times as UTC unless told otherwise.
+func (db *Database) readTime(t time.Time) time.Time {
+
+	if db == nil || db.TimePolicy.Location == nil {
+		return t
+	}
+	t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), db.TimePolicy.Location)
+	return db.readLocation(t)
+}
+
+func (db *Database) readLocation(t time.Time) time.Time {
+	if db != nil && db.TimePolicy.ReadLocation != nil {
+		return t.In(db.TimePolicy.ReadLocation)
+	}
+	return t
+}
@@ -0,0 +1,101 @@
+package gsdb
+
+import (
+	"context"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTimePolicyWrite(t *testing.T) {
+	New("test/test", slog.Default(), context.Background())
+	tokyo := time.FixedZone("JST", 9*60*60)
+	value := time.Date(2025, time.December, 25, 15, 29, 25, 123456789, tokyo)
+
+	// The default keeps the time as it is, to the second
+	assert.Equal(t, "2025-12-25 15:29:25", DB.formatTime(value))
+
+	DB.TimePolicy = TimePolicy{Location: time.UTC, Precision: 6}
+	assert.Equal(t, "2025-12-25 06:29:25.123456", DB.formatTime(value))
+
+	// MySQL stores at most microseconds
+	DB.TimePolicy.Precision = 9
+	assert.Equal(t, "2025-12-25 06:29:25.123456", DB.formatTime(value))
+	DB.TimePolicy.Precision = 6
+
+	entry := UpdatePersonTime{Id: 1, Name: "Test", Dtadded: value}
+	sql, err := DB.Update(entry)
+	assert.NoError(t, err)
+	assert.Equal(t, "UPDATE Users SET name=X'54657374',dtadded='2025-12-25 06:29:25.123456' WHERE id=1;", sql)
+
+	sql, err = DB.Insert(entry)
+	assert.NoError(t, err)
+	assert.Equal(t, "INSERT INTO Users(name,dtadded) VALUES (X'54657374','2025-12-25 06:29:25.123456');", sql)
+}
+
+func TestTimePolicyRead(t *testing.T) {
+	New("test/test", slog.Default(), context.Background())
+	tokyo := time.FixedZone("JST", 9*60*60)
+
+	// The default reads strings as UTC
+	assert.Equal(t, time.Date(2025, time.December, 25, 6, 29, 25, 0, time.UTC), Field{Value: "2025-12-25 06:29:25"}.AsDate(""))
+
+	DB.TimePolicy = TimePolicy{Location: tokyo, ReadLocation: time.UTC}
+	expected := time.Date(2025, time.December, 25, 6, 29, 25, 500000000, time.UTC)
+
+	assert.Equal(t, expected, Field{Value: "2025-12-25 15:29:25.5"}.AsDate(""))
+	assert.Equal(t, expected.Unix(), Field{Value: "2025-12-25 15:29:25.5"}.AsDateEpoch())
+
+	// Drivers label times as UTC, the wall clock is in the storage location
+	driverTime := time.Date(2025, time.December, 25, 15, 29, 25, 500000000, time.UTC)
+	assert.Equal(t, expected, Field{Value: driverTime}.AsDate(""))
+
+	got, err := Field{Value: "25/12/2025 15:29:25.5"}.Time("02/01/2006 15:04:05")
+	assert.NoError(t, err)
+	assert.Equal(t, expected, got)
+}
+
+func TestTimePolicyRoundTrip(t *testing.T) {
+	NewSQLite3("test.db", slog.Default(), context.Background())
+	DB.TimePolicy = TimePolicy{Location: time.UTC, Precision: 6, ReadLocation: time.Local}
+
+	_, err := DB.dbConnection.Exec("DROP TABLE IF EXISTS Users;")
+	assert.NoError(t, err)
+	_, err = DB.dbConnection.Exec("CREATE TABLE Users (id INTEGER PRIMARY KEY AUTOINCREMENT, name TEXT, dtadded TEXT);")
+	assert.NoError(t, err)
+
+	written := time.Date(2025, time.December, 25, 15, 29, 25, 123456000, time.FixedZone("X", -5*60*60))
+	sql, err := DB.Insert(UpdatePersonTime{Name: "Test", Dtadded: written})
+	assert.NoError(t, err)
+	_, _, err = DB.Execute(sql)
+	assert.NoError(t, err)
+
+	read, err := QuerySingleStruct[UpdatePersonTime]("SELECT * FROM Users")
+	assert.NoError(t, err)
+	assert.True(t, written.Equal(read.Dtadded), "got %v want %v", read.Dtadded, written)
+	assert.Equal(t, time.Local, read.Dtadded.Location())
+
+	records, err := DB.Query("SELECT dtadded FROM Users")
+	assert.NoError(t, err)
+	assert.Equal(t, "2025-12-25 20:29:25.123456", records[0]["dtadded"].AsString())
+}
+
+func TestTimePolicyOfReadingDatabase(t *testing.T) {
+	NewSQLite3("test.db", slog.Default(), context.Background())
+	tokyo := time.FixedZone("JST", 9*60*60)
+	reader := DB
+	reader.TimePolicy = TimePolicy{Location: tokyo, ReadLocation: time.UTC}
+
+	// Values keep the TimePolicy of the Database they were read with, even once DB is replaced
+	rs, err := reader.QueryResult("SELECT '2025-12-25 15:29:25' AS at")
+	assert.NoError(t, err)
+	New("test/test", slog.Default(), context.Background())
+
+	expected := time.Date(2025, time.December, 25, 6, 29, 25, 0, time.UTC)
+	assert.Equal(t, expected, rs.Rows[0][0].AsDate(""))
+	got, err := rs.Rows[0][0].Time("")
+	assert.NoError(t, err)
+	assert.Equal(t, expected, got)
+}
@@ -0,0 +1,45 @@
+package gsdb
+
+import (
+	"reflect"
+	"time"
+)
+
+// now returns the current time from the Database Clock, falling back to time.Now when no clock is set
+func (db *Database) now() time.Time {
+	if db == nil || db.Clock == nil {
+		return time.Now()
+	}
+	return db.Clock()
+}
+
+// applyAutoTimestamps sets the autocreate=yes (insert only) and autoupdate=yes time fields to the current time.
+// If dbStructure is a pointer, the fields are set on the struct it points to, so the caller sees the times that
+// were written. Otherwise a copy is updated. Either way the struct value to generate the SQL from is returned.
+func (db *Database) applyAutoTimestamps(dbStructure any, insert bool) any {
+
+	v := reflect.ValueOf(structPointer(dbStructure))
+	if v.Kind() != reflect.Pointer || v.IsNil() {
+		return dbStructure
+	}
+
+	v = v.Elem()
+	if v.Kind() != reflect.Struct {
+		return dbStructure
+	}
+
+	t := v.Type()
+	now := db.now()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.Type != reflect.TypeOf(time.Time{}) || !v.Field(i).CanSet() {
+			continue
+		}
+
+		dbStructureMap := decodeTag(field.Tag.Get("db"))
+		if dbStructureMap["autoupdate"] == "yes" || (insert && dbStructureMap["autocreate"] == "yes") {
+			v.Field(i).Set(reflect.ValueOf(now))
+		}
+	}
+	return v.Interface()
+}
@@ -0,0 +1,87 @@
+package gsdb
+
+import (
+	"context"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+type AutoTimePerson struct {
+	Id      int       `db:"column=id primarykey=yes table=Users"`
+	Name    string    `db:"column=name"`
+	Created time.Time `db:"column=created autocreate=yes"`
+	Updated time.Time `db:"column=updated autoupdate=yes"`
+}
+
+func setupClock() time.Time {
+	New("test/test", slog.Default(), context.Background())
+	return setupClockOn(DB)
+}
+
+// setupClockOn fixes the clock of the database to 2025-06-01 12:30:45 UTC
+func setupClockOn(db *Database) time.Time {
+	now := time.Date(2025, time.June, 1, 12, 30, 45, 0, time.UTC)
+	db.Clock = func() time.Time { return now }
+	return now
+}
+
+func TestAutoTimestampsInsert(t *testing.T) {
+	now := setupClock()
+
+	entry := AutoTimePerson{Name: "Test"}
+	sqlQuery, err := DB.Insert(entry)
+	assert.NoError(t, err)
+	assert.Equal(t, "INSERT INTO Users(name,created,updated) VALUES (X'54657374','2025-06-01 12:30:45','2025-06-01 12:30:45');", sqlQuery)
+	assert.True(t, entry.Created.IsZero(), "a struct passed by value must not be changed")
+
+	_, err = DB.Insert(&entry)
+	assert.NoError(t, err)
+	assert.Equal(t, now, entry.Created)
+	assert.Equal(t, now, entry.Updated)
+
+	sqlQuery, err = InsertMany([]AutoTimePerson{{Name: "Test"}})
+	assert.NoError(t, err)
+	assert.Equal(t, "INSERT INTO Users(name,created,updated) VALUES (X'54657374','2025-06-01 12:30:45','2025-06-01 12:30:45');", sqlQuery)
+}
+
+func TestAutoTimestampsUpdate(t *testing.T) {
+	now := setupClock()
+
+	created := time.Date(2020, time.January, 1, 0, 0, 0, 0, time.UTC)
+	entry := AutoTimePerson{Id: 3, Name: "Test", Created: created}
+
+	sqlQuery, err := DB.Update(&entry)
+	assert.NoError(t, err)
+	assert.Equal(t, "UPDATE Users SET name=X'54657374',created='2020-01-01 00:00:00',updated='2025-06-01 12:30:45' WHERE id=3;", sqlQuery)
+	assert.Equal(t, created, entry.Created)
+	assert.Equal(t, now, entry.Updated)
+}
+
+func TestAutoTimestampsSavePointer(t *testing.T) {
+	mock, expectedExec := setupSaveTestMock(t, `INSERT INTO Users(name,created,updated) VALUES (X'54657374','2025-06-01 12:30:45','2025-06-01 12:30:45');`)
+	expectedExec.WillReturnResult(sqlmock.NewResult(1, 1))
+	now := time.Date(2025, time.June, 1, 12, 30, 45, 0, time.UTC)
+	DB.Clock = func() time.Time { return now }
+
+	entry := AutoTimePerson{Name: "Test"}
+	_, _, err := DB.Save(&entry, entry.Id)
+	assert.NoError(t, err)
+	assert.NoError(t, (*mock).ExpectationsWereMet())
+	assert.Equal(t, now, entry.Created)
+	assert.Equal(t, now, entry.Updated)
+}
+
+func TestAutoTimestampsClockOfReceiver(t *testing.T) {
+	New("test/test", slog.Default(), context.Background())
+
+	// The Clock of the Database the statement is built by is used, not the one of DB
+	other := &Database{}
+	setupClockOn(other)
+	sqlQuery, err := other.Insert(AutoTimePerson{Name: "Test"})
+	assert.NoError(t, err)
+	assert.Equal(t, "INSERT INTO Users(name,created,updated) VALUES (X'54657374','2025-06-01 12:30:45','2025-06-01 12:30:45');", sqlQuery)
+}
@@ -0,0 +1,147 @@
+package gsdb
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Tracer starts spans for database operations. gsdb starts one span for each statement (Execute, Query, BEGIN,
+// COMMIT and ROLLBACK) and one around each QueryStruct, Save and WithTx, so statements are children of the operation
+// that ran them. Set Database.Tracer to an adapter for your tracing library, the default does nothing.
+//
+// Spans carry the attributes db.system (mysql or sqlite), db.statement (the statement shape, see NormalizeSQL,
+// so no values are included), db.operation and db.sql.table, and once ended db.rows_affected or db.rows_returned.
+type Tracer interface {
+	Start(ctx context.Context, name string) (context.Context, Span)
+}
+
+// Span is a single traced operation. End is called once, with the error the operation returned (if any).
+type Span interface {
+	SetAttribute(key string, value any)
+	End(err error)
+}
+
+// NoopTracer is the default Tracer, which records nothing
+type NoopTracer struct{}
+
+func (NoopTracer) Start(ctx context.Context, name string) (context.Context, Span) {
+	return ctx, noopSpan{}
+}
+
+type noopSpan struct{}
+
+func (noopSpan) SetAttribute(key string, value any) {}
+func (noopSpan) End(err error)                      {}
+
+// tracer returns the Database Tracer, or a NoopTracer if there isn't one
+func (db *Database) tracer() Tracer {
+	if db == nil || db.Tracer == nil {
+		return NoopTracer{}
+	}
+	return db.Tracer
+}
+
+// startSpan starts a span with the database attributes. sql can be empty for operations that aren't a statement.
+func (db *Database) startSpan(ctx context.Context, name string, sql string) (context.Context, Span) {
+
+	ctx, span := db.tracer().Start(ctx, name)
+
+	system := "mysql"
+	if db.sqlite() {
+		system = "sqlite"
+	}
+	span.SetAttribute("db.system", system)
+
+	if sql != "" {
+		operation, table := statementLabels(sql)
+		span.SetAttribute("db.statement", NormalizeSQL(sql))
+		span.SetAttribute("db.operation", operation)
+		if table != "" {
+			span.SetAttribute("db.sql.table", table)
+		}
+	}
+	return ctx, span
+}
+
+// RecordedSpan is a span kept by a SpanRecorder
+type RecordedSpan struct {
+	ID         int
+	ParentID   int // 0 for a span with no parent
+	Name       string
+	Attributes map[string]any
+	Err        error
+	Start      time.Time
+	End        time.Time
+	Ended      bool
+}
+
+// SpanRecorder is a Tracer that keeps the spans in memory, for tests
+//
+//	recorder := &gsdb.SpanRecorder{}
+//	gsdb.DB.Tracer = recorder
+//	...
+//	spans := recorder.Spans()
+type SpanRecorder struct {
+	lock  sync.Mutex
+	spans []*RecordedSpan
+}
+
+type recordedSpanKey struct{}
+
+func (r *SpanRecorder) Start(ctx context.Context, name string) (context.Context, Span) {
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	span := &RecordedSpan{ID: len(r.spans) + 1, Name: name, Attributes: make(map[string]any), Start: time.Now()}
+	if parent, ok := ctx.Value(recordedSpanKey{}).(*RecordedSpan); ok {
+		span.ParentID = parent.ID
+	}
+	r.spans = append(r.spans, span)
+	return context.WithValue(ctx, recordedSpanKey{}, span), &recorderSpan{recorder: r, span: span}
+}
+
+// Spans returns a copy of the spans started so far, in the order they were started
+func (r *SpanRecorder) Spans() []RecordedSpan {
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	spans := make([]RecordedSpan, 0, len(r.spans))
+	for _, span := range r.spans {
+		s := *span
+		s.Attributes = make(map[string]any, len(span.Attributes))
+		for k, v := range span.Attributes {
+			s.Attributes[k] = v
+		}
+		spans = append(spans, s)
+	}
+	return spans
+}
+
+// Reset forgets the spans recorded so far
+func (r *SpanRecorder) Reset() {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.spans = nil
+}
+
+type recorderSpan struct {
+	recorder *SpanRecorder
+	span     *RecordedSpan
+}
+
+func (s *recorderSpan) SetAttribute(key string, value any) {
+	s.recorder.lock.Lock()
+	defer s.recorder.lock.Unlock()
+	s.span.Attributes[key] = value
+}
+
+func (s *recorderSpan) End(err error) {
+	s.recorder.lock.Lock()
+	defer s.recorder.lock.Unlock()
+	s.span.Err = err
+	s.span.End = time.Now()
+	s.span.Ended = true
+}
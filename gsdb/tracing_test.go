@@ -0,0 +1,124 @@
+package gsdb
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func spanNames(spans []RecordedSpan) []string {
+	names := make([]string, 0, len(spans))
+	for _, s := range spans {
+		names = append(names, s.Name)
+	}
+	return names
+}
+
+func TestTracingQueryStruct(t *testing.T) {
+	setupPreloadTables(t)
+	recorder := &SpanRecorder{}
+	DB.Tracer = recorder
+
+	orders, err := QueryStruct[PreloadOrder]("SELECT * FROM orders WHERE customer_id=1", Preload("Lines"))
+	assert.NoError(t, err)
+	assert.Len(t, orders, 2)
+
+	spans := recorder.Spans()
+	assert.Equal(t, []string{"gsdb.QueryStruct", "gsdb.query", "gsdb.query"}, spanNames(spans))
+
+	queryStruct, query, preload := spans[0], spans[1], spans[2]
+	assert.Equal(t, 0, queryStruct.ParentID)
+	assert.Equal(t, queryStruct.ID, query.ParentID)
+	assert.Equal(t, queryStruct.ID, preload.ParentID, "preloading is part of the QueryStruct")
+
+	assert.Equal(t, "sqlite", query.Attributes["db.system"])
+	assert.Equal(t, "SELECT * FROM orders WHERE customer_id=?", query.Attributes["db.statement"], "values are not traced")
+	assert.Equal(t, "select", query.Attributes["db.operation"])
+	assert.Equal(t, "orders", query.Attributes["db.sql.table"])
+	assert.Equal(t, int64(2), query.Attributes["db.rows_returned"])
+	assert.Equal(t, "order_lines", preload.Attributes["db.sql.table"])
+	assert.Equal(t, int64(2), queryStruct.Attributes["db.rows_returned"])
+	for _, s := range spans {
+		assert.True(t, s.Ended, s.Name)
+		assert.NoError(t, s.Err, s.Name)
+	}
+
+	recorder.Reset()
+	_, err = QueryStruct[PreloadOrder]("SELECT * FROM missing")
+	assert.Error(t, err)
+	spans = recorder.Spans()
+	if assert.Len(t, spans, 2) {
+		assert.Error(t, spans[0].Err)
+		assert.Error(t, spans[1].Err)
+	}
+}
+
+func TestTracingSaveAndTx(t *testing.T) {
+	m, _ := setupHookMock(t)
+	recorder := &SpanRecorder{}
+	DB.Tracer = recorder
+
+	m.ExpectExec("INSERT INTO Users(name,status) VALUES (X'54657374',31);").WillReturnResult(sqlmock.NewResult(5, 1))
+	_, _, err := DB.Save(SavePersonTime{Name: "Test", Status: 31}, 0)
+	assert.NoError(t, err)
+
+	spans := recorder.Spans()
+	assert.Equal(t, []string{"gsdb.Save", "gsdb.exec"}, spanNames(spans))
+	assert.Equal(t, "Users", spans[0].Attributes["db.sql.table"])
+	assert.Equal(t, int64(1), spans[0].Attributes["db.rows_affected"])
+	assert.Equal(t, spans[0].ID, spans[1].ParentID)
+	assert.Equal(t, "INSERT INTO Users(name,status) VALUES (?)", spans[1].Attributes["db.statement"])
+
+	recorder.Reset()
+	failed := errors.New("failed")
+	m.ExpectBegin()
+	m.ExpectExec("DELETE FROM Users").WillReturnResult(sqlmock.NewResult(0, 2))
+	m.ExpectRollback()
+	err = DB.WithTx(context.Background(), func(tx *Tx) error {
+		if _, _, err := tx.Execute("DELETE FROM Users"); err != nil {
+			return err
+		}
+		return failed
+	})
+	assert.ErrorIs(t, err, failed)
+	assert.NoError(t, m.ExpectationsWereMet())
+
+	spans = recorder.Spans()
+	assert.Equal(t, []string{"gsdb.Tx", "gsdb.begin", "gsdb.exec", "gsdb.rollback"}, spanNames(spans))
+	for _, s := range spans[1:] {
+		assert.Equal(t, spans[0].ID, s.ParentID, s.Name)
+	}
+	assert.ErrorIs(t, spans[0].Err, failed)
+	assert.Equal(t, int64(2), spans[2].Attributes["db.rows_affected"])
+}
+
+func TestTracingTxPanic(t *testing.T) {
+	m, _ := setupHookMock(t)
+	recorder := &SpanRecorder{}
+	DB.Tracer = recorder
+
+	m.ExpectBegin()
+	m.ExpectRollback()
+	assert.PanicsWithValue(t, "boom", func() {
+		DB.WithTx(context.Background(), func(tx *Tx) error {
+			panic("boom")
+		})
+	})
+	assert.NoError(t, m.ExpectationsWereMet())
+
+	spans := recorder.Spans()
+	assert.Equal(t, []string{"gsdb.Tx", "gsdb.begin", "gsdb.rollback"}, spanNames(spans))
+	assert.True(t, spans[0].Ended)
+	assert.EqualError(t, spans[0].Err, "panic: boom")
+}
+
+func TestNoopTracer(t *testing.T) {
+	setupPreloadTables(t)
+	DB.Tracer = nil
+
+	_, err := QueryStruct[PreloadOrder]("SELECT * FROM orders")
+	assert.NoError(t, err)
+}
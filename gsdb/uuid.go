@@ -0,0 +1,236 @@
+package gsdb
+
+import (
+	"crypto/rand"
+	"database/sql/driver"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// UUID is a 128 bit key, stored as BINARY(16) in MySQL and as its text form in SQLite. Fields of type UUID or
+// [16]byte are encoded for the current Database when written, in WHERE clauses and as query parameters, and
+// read back from either form.
+//
+// Tag the primary key with generate=uuidv7 or generate=ulid and Insert (and so Save) fills it in when it's zero:
+//
+//	Id gsdb.UUID `db:"column=id primarykey=yes generate=uuidv7 table=accounts"`
+//
+// A string field with generate= gets the text form instead.
+type UUID [16]byte
+
+const ulidAlphabet = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+
+// NewUUIDv7 makes a time ordered version 7 UUID, using the Database Clock for the timestamp
+func NewUUIDv7() UUID {
+	return DB.newUUIDv7()
+}
+
+// NewULID makes a ULID, a 48 bit millisecond timestamp followed by 80 random bits, using the Database Clock
+func NewULID() UUID {
+	return DB.timeOrderedKey()
+}
+
+func (db *Database) newUUIDv7() UUID {
+	u := db.timeOrderedKey()
+	u[6] = 0x70 | u[6]&0x0f // version 7
+	u[8] = 0x80 | u[8]&0x3f // RFC 4122 variant
+	return u
+}
+
+// timeOrderedKey is the millisecond timestamp in the first 6 bytes, with the rest random
+func (db *Database) timeOrderedKey() UUID {
+	var u UUID
+	_, _ = rand.Read(u[6:])
+	ms := uint64(db.now().UnixMilli())
+	for i := 5; i >= 0; i-- {
+		u[i] = byte(ms)
+		ms >>= 8
+	}
+	return u
+}
+
+// ParseUUID reads a UUID written as 8-4-4-4-12 hex digits, 32 hex digits, or a 26 character ULID
+func ParseUUID(s string) (UUID, error) {
+
+	var u UUID
+	s = strings.TrimSpace(s)
+
+	if len(s) == 26 {
+		return parseULID(s)
+	}
+	if len(s) == 36 && s[8] == '-' && s[13] == '-' && s[18] == '-' && s[23] == '-' {
+		s = s[:8] + s[9:13] + s[14:18] + s[19:23] + s[24:]
+	}
+	if len(s) != 32 {
+		return u, fmt.Errorf("invalid uuid %q", s)
+	}
+	if _, err := hex.Decode(u[:], []byte(s)); err != nil {
+		return u, fmt.Errorf("invalid uuid %q", s)
+	}
+	return u, nil
+}
+
+func parseULID(s string) (UUID, error) {
+
+	var u UUID
+	var hi, lo uint64
+	for i, c := range strings.ToUpper(s) {
+		v := strings.IndexRune(ulidAlphabet, c)
+		if v == -1 || (i == 0 && v > 7) {
+			return u, fmt.Errorf("invalid ulid %q", s)
+		}
+		hi = hi<<5 | lo>>59
+		lo = lo<<5 | uint64(v)
+	}
+	binary.BigEndian.PutUint64(u[:8], hi)
+	binary.BigEndian.PutUint64(u[8:], lo)
+	return u, nil
+}
+
+// String returns the 8-4-4-4-12 text form
+func (u UUID) String() string {
+	h := hex.EncodeToString(u[:])
+	return h[:8] + "-" + h[8:12] + "-" + h[12:16] + "-" + h[16:20] + "-" + h[20:]
+}
+
+// ULID returns the 26 character Crockford base32 form
+func (u UUID) ULID() string {
+	hi := binary.BigEndian.Uint64(u[:8])
+	lo := binary.BigEndian.Uint64(u[8:])
+
+	out := make([]byte, 26)
+	for i := 25; i >= 0; i-- {
+		out[i] = ulidAlphabet[lo&31]
+		lo = lo>>5 | hi<<59
+		hi >>= 5
+	}
+	return string(out)
+}
+
+// IsZero reports whether all 16 bytes are zero, which Save takes to mean the row hasn't been inserted
+func (u UUID) IsZero() bool {
+	return u == UUID{}
+}
+
+// Value implements driver.Valuer, giving the 16 bytes for MySQL and the text form for SQLite. It follows DB,
+// as a Valuer can't know which Database it's passed to; gsdb passes UUIDs to its own queries with driverValue.
+func (u UUID) Value() (driver.Value, error) {
+	return u.driverValue(DB), nil
+}
+
+// driverValue is the UUID as a query parameter for the Database
+func (u UUID) driverValue(db *Database) driver.Value {
+	if db.sqlite() {
+		return u.String()
+	}
+	return u[:]
+}
+
+// Scan implements sql.Scanner. It takes the 16 raw bytes, the text form or a ULID, as a string or []byte.
+func (u *UUID) Scan(src any) error {
+
+	var s string
+	switch v := src.(type) {
+	case nil:
+		*u = UUID{}
+		return nil
+	case string:
+		s = v
+	case []byte:
+		s = string(v)
+	default:
+		return fmt.Errorf("cannot scan %T into a UUID", src)
+	}
+
+	if len(s) == 16 {
+		copy(u[:], s)
+		return nil
+	}
+	parsed, err := ParseUUID(s)
+	if err != nil {
+		return err
+	}
+	*u = parsed
+	return nil
+}
+
+// sqlLiteral is the UUID as it's written into generated SQL: X'..' for BINARY(16), or the quoted text form.
+func (u UUID) sqlLiteral(db *Database) string {
+	if db.sqlite() {
+		return "'" + u.String() + "'"
+	}
+	return fmt.Sprintf("X'%x'", u[:])
+}
+
+// isUUIDType reports whether a field holds a UUID, either as UUID or as a plain [16]byte
+func isUUIDType(t reflect.Type) bool {
+	return t == reflect.TypeOf(UUID{}) || t == reflect.TypeOf([16]byte{})
+}
+
+// uuidValue turns a [16]byte into a UUID, so it is encoded the same way. Anything else is returned as it is.
+func uuidValue(value any) any {
+	if b, ok := value.([16]byte); ok {
+		return UUID(b)
+	}
+	return value
+}
+
+// keyParameter encodes UUID and [16]byte keys as query parameters for the Database. Anything else is returned
+// as it is.
+func (db *Database) keyParameter(value any) any {
+	if u, ok := uuidValue(value).(UUID); ok {
+		return u.driverValue(db)
+	}
+	return value
+}
+
+// writableKey reports whether Insert writes the primary key. Keys that gsdb generates, and UUID keys, are made
+// by the client rather than the database.
+func writableKey(field reflect.StructField, dbStructureMap map[string]string) bool {
+	return dbStructureMap["primarykey"] == "yes" && dbStructureMap["omit"] != "yes" && dbStructureMap["readonly"] != "yes" &&
+		(dbStructureMap["generate"] != "" || isUUIDType(field.Type))
+}
+
+// applyGeneratedKeys fills in zero generate=uuidv7 and generate=ulid fields of the struct dbStructure points to
+func (db *Database) applyGeneratedKeys(dbStructure any) error {
+
+	v := reflect.ValueOf(dbStructure)
+	if v.Kind() != reflect.Pointer || v.IsNil() || v.Elem().Kind() != reflect.Struct {
+		return nil
+	}
+	v = v.Elem()
+
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		generate := decodeTag(field.Tag.Get("db"))["generate"]
+		if generate == "" || !v.Field(i).CanSet() || !v.Field(i).IsZero() {
+			continue
+		}
+
+		var key UUID
+		switch generate {
+		case "uuidv7":
+			key = db.newUUIDv7()
+		case "ulid":
+			key = db.timeOrderedKey()
+		default:
+			return fmt.Errorf("unknown generate=%s on field %s", generate, field.Name)
+		}
+
+		switch {
+		case isUUIDType(field.Type):
+			v.Field(i).Set(reflect.ValueOf(key).Convert(field.Type))
+		case field.Type.Kind() == reflect.String && generate == "ulid":
+			v.Field(i).SetString(key.ULID())
+		case field.Type.Kind() == reflect.String:
+			v.Field(i).SetString(key.String())
+		default:
+			return fmt.Errorf("generate=%s field %s must be a UUID, [16]byte or string", generate, field.Name)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,184 @@
+package gsdb
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+type UUIDAccount struct {
+	Id   UUID   `db:"column=id primarykey=yes generate=uuidv7 table=accounts"`
+	Name string `db:"column=name"`
+}
+
+type UUIDEvent struct {
+	Id        [16]byte `db:"column=id primarykey=yes table=events"`
+	AccountId UUID     `db:"column=account_id"`
+	Ref       string   `db:"column=ref generate=ulid"`
+}
+
+func TestParseUUID(t *testing.T) {
+	u, err := ParseUUID("0190a6c2-5b1e-7c3d-8e4f-a1b2c3d4e5f6")
+	assert.NoError(t, err)
+	assert.Equal(t, "0190a6c2-5b1e-7c3d-8e4f-a1b2c3d4e5f6", u.String())
+
+	same, err := ParseUUID("0190A6C25B1E7C3D8E4FA1B2C3D4E5F6")
+	assert.NoError(t, err)
+	assert.Equal(t, u, same)
+
+	for _, in := range []string{"", "0190a6c2", "0190a6c2-5b1e-7c3d-8e4f-a1b2c3d4e5fz", "8ZZZZZZZZZZZZZZZZZZZZZZZZZ"} {
+		_, err := ParseUUID(in)
+		assert.Error(t, err, in)
+	}
+
+	assert.Equal(t, "00000000000000000000000000", UUID{}.ULID())
+	max := UUID{0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff}
+	assert.Equal(t, "7ZZZZZZZZZZZZZZZZZZZZZZZZZ", max.ULID())
+
+	ulid, err := ParseUUID("01ARZ3NDEKTSV4RRFFQ69G5FAV")
+	assert.NoError(t, err)
+	assert.Equal(t, "01ARZ3NDEKTSV4RRFFQ69G5FAV", ulid.ULID())
+}
+
+func TestNewUUIDv7(t *testing.T) {
+	now := setupClock()
+
+	u := NewUUIDv7()
+	assert.Equal(t, byte(0x70), u[6]&0xf0, "version")
+	assert.Equal(t, byte(0x80), u[8]&0xc0, "variant")
+	assert.Equal(t, fmt.Sprintf("%012x", now.UnixMilli()), fmt.Sprintf("%x", u[:6]))
+	assert.NotEqual(t, u, NewUUIDv7())
+
+	ulid := NewULID()
+	assert.Equal(t, u[:6], ulid[:6])
+}
+
+func TestUUIDMySQL(t *testing.T) {
+	setupClock()
+
+	account := UUIDAccount{Name: "Test"}
+	sql, err := DB.Insert(&account)
+	assert.NoError(t, err)
+	assert.False(t, account.Id.IsZero())
+	assert.Equal(t, fmt.Sprintf("INSERT INTO accounts(id,name) VALUES (X'%x',X'54657374');", account.Id[:]), sql)
+
+	sql, err = DB.Update(account)
+	assert.NoError(t, err)
+	assert.Equal(t, fmt.Sprintf("UPDATE accounts SET name=X'54657374' WHERE id=X'%x';", account.Id[:]), sql)
+
+	sql, err = DB.Delete(account)
+	assert.NoError(t, err)
+	assert.Equal(t, fmt.Sprintf("DELETE FROM accounts WHERE id=X'%x';", account.Id[:]), sql)
+
+	// A key that's already set is kept, and [16]byte is written the same way as UUID
+	event := UUIDEvent{Id: [16]byte{1, 2, 3}, AccountId: account.Id, Ref: "fixed"}
+	sql, err = DB.Insert(event)
+	assert.NoError(t, err)
+	assert.Equal(t, fmt.Sprintf("INSERT INTO events(id,account_id,ref) VALUES (X'01020300000000000000000000000000',X'%x',X'%x');", account.Id[:], "fixed"), sql)
+}
+
+func TestUUIDMySQLRead(t *testing.T) {
+	New("test/test", slog.Default(), context.Background())
+	db, m, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	assert.NoError(t, err)
+	DB.dbConnection = db
+	DB.connected = true
+
+	id, _ := ParseUUID("0190a6c2-5b1e-7c3d-8e4f-a1b2c3d4e5f6")
+	m.ExpectQuery("SELECT * FROM accounts WHERE id=?;").WithArgs(id[:]).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(id[:], "Test"))
+
+	account, err := FindByPK[UUIDAccount](id)
+	assert.NoError(t, err)
+	assert.NoError(t, m.ExpectationsWereMet())
+	assert.Equal(t, id, account.Id)
+	assert.Equal(t, "Test", account.Name)
+}
+
+func TestUUIDSQLite(t *testing.T) {
+	NewSQLite3("test.db", slog.Default(), context.Background())
+	setupClockOn(DB)
+
+	for _, sql := range []string{
+		"DROP TABLE IF EXISTS accounts;",
+		"DROP TABLE IF EXISTS events;",
+		"CREATE TABLE accounts (id TEXT PRIMARY KEY, name TEXT);",
+		"CREATE TABLE events (id TEXT PRIMARY KEY, account_id TEXT, ref TEXT);",
+	} {
+		_, err := DB.dbConnection.Exec(sql)
+		assert.NoError(t, err)
+	}
+
+	account := UUIDAccount{Name: "Test"}
+	_, _, err := DB.Save(&account, account.Id)
+	assert.NoError(t, err)
+	assert.False(t, account.Id.IsZero())
+
+	// Stored as text, so it can be matched by hand too
+	records, err := DB.Query("SELECT name FROM accounts WHERE id=?", account.Id.String())
+	assert.NoError(t, err)
+	assert.Len(t, records, 1)
+
+	account.Name = "Changed"
+	_, rowsAffected, err := DB.Save(&account, account.Id)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), rowsAffected)
+
+	found, err := FindByPK[UUIDAccount](account.Id)
+	assert.NoError(t, err)
+	assert.Equal(t, account, found)
+
+	event := UUIDEvent{Id: NewUUIDv7(), AccountId: account.Id}
+	sql, err := DB.Insert(&event)
+	assert.NoError(t, err)
+	assert.Len(t, event.Ref, 26)
+	_, _, err = DB.Execute(sql)
+	assert.NoError(t, err)
+
+	read, err := QuerySingleStruct[UUIDEvent]("SELECT * FROM events WHERE account_id=?", account.Id)
+	assert.NoError(t, err)
+	assert.Equal(t, event, read)
+}
+
+func TestUUIDOfReceiver(t *testing.T) {
+	now := setupClock()
+	sqlite := &Database{driverName: "sqlite3", Clock: func() time.Time { return now.Add(time.Hour) }}
+
+	// The key takes its time from, and is written for, the Database building the statement rather than DB
+	account := UUIDAccount{Name: "Test"}
+	sql, err := sqlite.Insert(&account)
+	assert.NoError(t, err)
+	assert.Equal(t, now.Add(time.Hour).UnixMilli(), int64(binary.BigEndian.Uint64(append([]byte{0, 0}, account.Id[:6]...))))
+	assert.Equal(t, fmt.Sprintf("INSERT INTO accounts(id,name) VALUES ('%s',X'54657374');", account.Id), sql)
+
+	assert.Equal(t, []any{account.Id.String()}, sqlite.uniqueKeys([]any{account.Id, [16]byte(account.Id)}))
+	assert.Equal(t, []any{account.Id[:]}, DB.uniqueKeys([]any{account.Id}))
+}
+
+func TestInsertManyWritesBack(t *testing.T) {
+	now := setupClock()
+
+	// Generated keys and autotimestamps land in the caller's slice, for structs and pointers alike
+	accounts := []UUIDAccount{{Name: "First"}, {Name: "Second"}}
+	sql, err := InsertMany(accounts)
+	assert.NoError(t, err)
+	assert.False(t, accounts[0].Id.IsZero())
+	assert.NotEqual(t, accounts[0].Id, accounts[1].Id)
+	assert.Equal(t, fmt.Sprintf("INSERT INTO accounts(id,name) VALUES (X'%x',X'4669727374')\n(X'%x',X'5365636f6e64');", accounts[0].Id[:], accounts[1].Id[:]), sql)
+
+	people := []*AutoTimePerson{{Name: "Test"}}
+	sql, err = InsertMany(people)
+	assert.NoError(t, err)
+	assert.Equal(t, "INSERT INTO Users(name,created,updated) VALUES (X'54657374','2025-06-01 12:30:45','2025-06-01 12:30:45');", sql)
+	assert.Equal(t, now, people[0].Created)
+	assert.Equal(t, now, people[0].Updated)
+
+	_, err = InsertMany([]*AutoTimePerson{nil})
+	assert.EqualError(t, err, "element 0 is nil")
+}
@@ -0,0 +1,192 @@
+package gsdb
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+// FieldError is a single failed validation rule
+type FieldError struct {
+	Field   string
+	Column  string
+	Rule    string
+	Message string
+}
+
+// ValidationError is returned by Insert, InsertMany, Update and Save when the structure breaks any of the
+// required, maxlen, min, max or pattern rules in its tags. It lists every failed field, not just the first.
+type ValidationError struct {
+	Errors []FieldError
+}
+
+func (e *ValidationError) Error() string {
+	messages := make([]string, 0, len(e.Errors))
+	for _, fe := range e.Errors {
+		messages = append(messages, fe.Message)
+	}
+	return "validation failed: " + strings.Join(messages, "; ")
+}
+
+var patternCache sync.Map
+
+// Validate checks the validation tags of a structure, returning a *ValidationError if any fail.
+// Only columns that are written to the database are checked.
+func Validate(dbStructure any) error {
+	return DB.validate(dbStructure)
+}
+
+// validate checks the validation tags of a structure, naming the columns with the NamingStrategy of the Database
+func (db *Database) validate(dbStructure any) error {
+
+	v := reflect.Indirect(reflect.ValueOf(dbStructure))
+	if v.Kind() != reflect.Struct {
+		return nil
+	}
+
+	t := v.Type()
+	var failed []FieldError
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		dbStructureMap := decodeTag(field.Tag.Get("db"))
+
+		if !field.IsExported() || !writableColumn(dbStructureMap) {
+			continue
+		}
+
+		column := db.columnName(field, dbStructureMap)
+		fail := func(rule string, format string, args ...any) {
+			failed = append(failed, FieldError{
+				Field:   field.Name,
+				Column:  column,
+				Rule:    rule,
+				Message: column + " " + fmt.Sprintf(format, args...),
+			})
+		}
+		value := v.Field(i)
+
+		if dbStructureMap["required"] == "yes" && value.IsZero() {
+			fail("required", "is required")
+			continue
+		}
+
+		if maxlen, ok := dbStructureMap["maxlen"]; ok {
+			limit, err := strconv.Atoi(maxlen)
+			switch {
+			case err != nil:
+				fail("maxlen", "has an invalid maxlen %q", maxlen)
+			case value.Kind() == reflect.String && utf8.RuneCountInString(value.String()) > limit:
+				fail("maxlen", "is longer than %d characters", limit)
+			case value.Kind() == reflect.Slice && value.Len() > limit:
+				fail("maxlen", "is longer than %d bytes", limit)
+			}
+		}
+
+		for _, rule := range []string{"min", "max"} {
+			bound, ok := dbStructureMap[rule]
+			if !ok {
+				continue
+			}
+			cmp, ok, err := compareBound(value, bound)
+			if err != nil {
+				fail(rule, "has an invalid %s %q", rule, bound)
+				continue
+			}
+			if !ok {
+				continue
+			}
+			if rule == "min" && cmp < 0 {
+				fail(rule, "must be at least %s", bound)
+			}
+			if rule == "max" && cmp > 0 {
+				fail(rule, "must be at most %s", bound)
+			}
+		}
+
+		if pattern, ok := dbStructureMap["pattern"]; ok && value.Kind() == reflect.String {
+			re, err := compilePattern(pattern)
+			if err != nil {
+				fail("pattern", "has an invalid pattern %q", pattern)
+			} else if !re.MatchString(value.String()) {
+				fail("pattern", "does not match %s", pattern)
+			}
+		}
+	}
+
+	if len(failed) > 0 {
+		return &ValidationError{Errors: failed}
+	}
+	return nil
+}
+
+// compareBound compares a value with a min or max bound, returning -1, 0 or +1. Decimals are compared exactly.
+// ok is false for values that aren't numbers, such as a nil *Decimal, which aren't checked.
+func compareBound(v reflect.Value, bound string) (cmp int, ok bool, err error) {
+
+	if d, isDecimal := decimalValue(v); isDecimal {
+		limit, err := ParseDecimal(bound)
+		if err != nil {
+			return 0, false, err
+		}
+		if d == nil {
+			return 0, false, nil
+		}
+		return d.Cmp(limit), true, nil
+	}
+
+	limit, err := strconv.ParseFloat(bound, 64)
+	if err != nil {
+		return 0, false, err
+	}
+	number, ok := numericValue(v)
+	if !ok {
+		return 0, false, nil
+	}
+	switch {
+	case number < limit:
+		return -1, true, nil
+	case number > limit:
+		return 1, true, nil
+	}
+	return 0, true, nil
+}
+
+// decimalValue returns the Decimal held by a Decimal or *Decimal field, nil for a nil *Decimal
+func decimalValue(v reflect.Value) (*Decimal, bool) {
+	switch d := v.Interface().(type) {
+	case Decimal:
+		return &d, true
+	case *Decimal:
+		return d, true
+	}
+	return nil, false
+}
+
+func numericValue(v reflect.Value) (float64, bool) {
+	switch {
+	case v.CanInt():
+		return float64(v.Int()), true
+	case v.CanUint():
+		return float64(v.Uint()), true
+	case v.CanFloat():
+		return v.Float(), true
+	}
+	return 0, false
+}
+
+func compilePattern(pattern string) (*regexp.Regexp, error) {
+	if re, ok := patternCache.Load(pattern); ok {
+		return re.(*regexp.Regexp), nil
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, err
+	}
+	patternCache.Store(pattern, re)
+	return re, nil
+}
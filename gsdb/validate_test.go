@@ -0,0 +1,96 @@
+package gsdb
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type ValidatedPerson struct {
+	Id       int     `db:"column=id primarykey=yes table=Users required=yes"`
+	Name     string  `db:"column=name required=yes maxlen=5"`
+	Username string  `db:"column=username pattern='^[a-z]+$'"`
+	Age      int     `db:"column=age min=0 max=130"`
+	Score    float64 `db:"column=score min=0.5"`
+	Note     string  `db:"column=note readonly=yes required=yes"`
+}
+
+func TestDecodeTagQuotedValues(t *testing.T) {
+	m := decodeTag(`column=code pattern="^[a-z]+ =$" flag`)
+	assert.Equal(t, map[string]string{"column": "code", "pattern": "^[a-z]+ =$", "flag": ""}, m)
+}
+
+func TestValidate(t *testing.T) {
+	valid := ValidatedPerson{Name: "Bob", Username: "bob", Age: 30, Score: 1}
+	assert.NoError(t, Validate(valid))
+	assert.NoError(t, Validate(&valid))
+
+	err := Validate(ValidatedPerson{Name: "Robert", Username: "Bob1", Age: -1, Score: 0.1})
+
+	var validationError *ValidationError
+	assert.True(t, errors.As(err, &validationError))
+	assert.Equal(t, []FieldError{
+		{Field: "Name", Column: "name", Rule: "maxlen", Message: "name is longer than 5 characters"},
+		{Field: "Username", Column: "username", Rule: "pattern", Message: "username does not match ^[a-z]+$"},
+		{Field: "Age", Column: "age", Rule: "min", Message: "age must be at least 0"},
+		{Field: "Score", Column: "score", Rule: "min", Message: "score must be at least 0.5"},
+	}, validationError.Errors)
+	assert.EqualError(t, err, "validation failed: name is longer than 5 characters; username does not match ^[a-z]+$; age must be at least 0; score must be at least 0.5")
+
+	err = Validate(ValidatedPerson{Username: "bob", Age: 131, Score: 1})
+	assert.EqualError(t, err, "validation failed: name is required; age must be at most 130")
+}
+
+func TestValidateBeforeWrite(t *testing.T) {
+	invalid := ValidatedPerson{Id: 1, Username: "bob"}
+
+	_, err := DB.Insert(invalid)
+	assert.EqualError(t, err, "validation failed: name is required; score must be at least 0.5")
+
+	_, err = InsertMany([]ValidatedPerson{invalid})
+	assert.EqualError(t, err, "validation failed: name is required; score must be at least 0.5")
+
+	_, err = DB.Update(invalid)
+	assert.EqualError(t, err, "validation failed: name is required; score must be at least 0.5")
+
+	mock, _ := setupSaveTestMock(t, `UPDATE Users SET name=X'',username=X'626f62',age=0,score=0 WHERE id=1;`)
+	_, _, err = DB.Save(invalid, invalid.Id)
+	var validationError *ValidationError
+	assert.ErrorAs(t, err, &validationError)
+	assert.Error(t, (*mock).ExpectationsWereMet(), "nothing should have been executed")
+}
+
+func TestValidateNamingOfReceiver(t *testing.T) {
+	snake := &Database{NamingStrategy: SnakeCase}
+	entry := struct {
+		FirstName string `db:"required=yes"`
+	}{}
+
+	_, err := snake.Insert(entry)
+	assert.EqualError(t, err, "validation failed: first_name is required")
+}
+
+func TestValidateDecimalBounds(t *testing.T) {
+	type Payment struct {
+		Amount Decimal  `db:"column=amount min=0.01 max=99999999999999999.99"`
+		Fee    *Decimal `db:"column=fee max=0.10"`
+	}
+
+	// Too many digits for a float64 to tell these apart
+	largest, _ := ParseDecimal("99999999999999999.99")
+	assert.NoError(t, Validate(Payment{Amount: largest}))
+
+	fee := NewDecimal(11, 2)
+	over, _ := ParseDecimal("100000000000000000.00")
+	err := Validate(Payment{Amount: over, Fee: &fee})
+	assert.EqualError(t, err, "validation failed: amount must be at most 99999999999999999.99; fee must be at most 0.10")
+
+	err = Validate(Payment{Amount: NewDecimal(0, 2)})
+	assert.EqualError(t, err, "validation failed: amount must be at least 0.01")
+
+	err = Validate(struct {
+		Amount Decimal `db:"column=amount min=1e3"`
+	}{})
+	assert.EqualError(t, err, `validation failed: amount has an invalid min "1e3"`)
+}